@@ -2,7 +2,15 @@ package argparse
 
 type Type = any
 type TypeFunc = func(string) (any, error)
-type NewActionFuncType = func(*Argument) *Action
+
+// ActionFactory creates the action of an argument, it is registered under
+// the name given as Action to AddArgument with RegisterAction. Custom
+// actions usually embed the *Action returned by NewAction, which provides
+// GetMap, FormatUsage, Struct and GetSubActions_, and implement Call.
+type ActionFactory = func(argument *Argument) ActionInterface
+
+// Deprecated: use ActionFactory.
+type NewActionFuncType = ActionFactory
 
 type ActionInterface interface {
 	GetMap() map[string]any
@@ -27,10 +35,20 @@ type Action struct {
 	Help          string   // The help description for the argument
 	MetaVar       any      // The name to be used in help output
 	Deprecated    bool     // Whether the argument is deprecated
+	Env           string   // The environment variable used when the option is absent
+	Prompt        string   // The question asked for the argument if it is missing
+	PromptHidden  bool     // Whether the answer to the prompt is not echoed
+	Sensitive     bool     // Whether the value is a secret
 
+	Completer CompleterFunc // The function completing the argument values in the shell
+	Validate  ValidateFunc  // The function validating the converted values
+	Separator string        // The separator splitting each argument into a list
 	Container ActionsContainerInterface
+
+	kind string // The name the action was registered with, set by AddArgument
 }
 
+// NewAction creates the base of an action with the fields of argument.
 func NewAction(argument *Argument) *Action {
 	return &Action{
 		OptionStrings: argument.OptionStrings,
@@ -44,6 +62,13 @@ func NewAction(argument *Argument) *Action {
 		Help:          argument.Help,
 		MetaVar:       argument.MetaVar,
 		Deprecated:    argument.Deprecated,
+		Env:           argument.Env,
+		Prompt:        argument.Prompt,
+		PromptHidden:  argument.PromptHidden,
+		Sensitive:     argument.Sensitive,
+		Completer:     argument.Completer,
+		Validate:      argument.Validate,
+		Separator:     argument.Separator,
 	}
 }
 
@@ -65,6 +90,7 @@ func (a *Action) GetMap() map[string]any {
 		"Help":          a.Help,
 		"MetaVar":       a.MetaVar,
 		"Deprecated":    a.Deprecated,
+		"Env":           a.Env,
 	}
 }
 
@@ -81,6 +107,7 @@ func (a *Action) Call(parser *ArgumentParser, namespace *Namespace, values any,
 	panic("action.Call() not implemented")
 }
 
+// GetSubActions_ returns the actions shown under this action in the help, none by default.
 func (a *Action) GetSubActions_() []ActionInterface {
 	// panic("action.GetSubActions() not implemented")
 	return nil
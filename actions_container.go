@@ -3,6 +3,7 @@ package argparse
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +18,7 @@ type ConflictingOption struct {
 type ActionsContainerInterface interface {
 	Struct() *ActionsContainer                                                     // +
 	Register(string, any, any)                                                     // +
+	RegisterAction(string, ActionFactory)                                          // +
 	RegistryGet(string, any, any) any                                              // +
 	AddArgument(*Argument) ActionInterface                                         // ?
 	SetDefaults(map[string]any)                                                    // +
@@ -33,7 +35,7 @@ type ActionsContainerInterface interface {
 	HandleConflictError(ActionInterface, []ConflictingOption)                      // ?
 	HandleConflictResolve(ActionInterface, []ConflictingOption)                    // ?
 	CheckHelp(action ActionInterface)                                              // ?
-	GetFormatter_() any                                                            // -, not done for ArgumentParser
+	GetFormatter_() HelpFormatterInterface                                         // +
 }
 
 type ActionsContainer struct {
@@ -50,6 +52,10 @@ type ActionsContainer struct {
 	NegativeNumberMatcher      *regexp.Regexp
 	HasNegativeNumberOptionals []bool
 
+	// The parser or group embedding the container, through which the
+	// overridable methods are called
+	Container ActionsContainerInterface
+
 	// GetFormatter any
 	Title    string
 	Required bool
@@ -76,11 +82,13 @@ func NewActionsContainer(
 		OptionStringActions:        make(map[string]ActionInterface),
 		ActionGroups:               []ActionsContainerInterface{}, // groups
 		MutuallyExclusiveGroups:    []ActionsContainerInterface{},
-		Defaults:                   make(map[string]any),            // defaults storage
-		NegativeNumberMatcher:      regexp.MustCompile(`-\.?(\d+)`), // determines whether an "option" looks like a negative number
-		HasNegativeNumberOptionals: []bool{},                        // # whether or not there are any optionals that look like negative numbers -- uses a list so it can be shared and edited
+		Defaults:                   make(map[string]any),             // defaults storage
+		NegativeNumberMatcher:      regexp.MustCompile(`^-\.?(\d+)`), // determines whether an "option" looks like a negative number
+		HasNegativeNumberOptionals: []bool{},                         // # whether or not there are any optionals that look like negative numbers -- uses a list so it can be shared and edited
 	}
 
+	container.Container = container
+
 	// register actions
 	container.Register("action", "", NewStoreAction)
 	container.Register("action", "store", NewStoreAction)
@@ -92,8 +100,30 @@ func NewActionsContainer(
 	container.Register("action", "count", NewCountAction)
 	container.Register("action", "help", NewHelpAction)
 	container.Register("action", "version", NewVersionAction)
-	container.Register("action", "parsers", NewSubParsersAction)
+	container.Register("action", "version_json", NewVersionJSONAction)
+	container.Register("action", "boolean_optional", NewBooleanOptionalAction)
+	container.Register("action", "parsers", newParsersAction)
 	container.Register("action", "extend", NewExtendAction)
+	container.Register("action", "map", NewMapAction)
+	container.Register("action", "extend_map", NewExtendMapAction)
+	container.Register("action", "secret_file", NewSecretFileAction)
+	container.Register("action", "secret_stdin", NewSecretStdinAction)
+	container.Register("action", "config", NewConfigAction)
+	container.Register("action", "print_config", NewPrintConfigAction)
+
+	// register types
+	container.Register("type", nil, identity)
+	container.Register("type", "int", intType)
+	container.Register("type", "float", floatType)
+
+	// register configuration file parsers
+	container.Register("config", ".json", ParseJSONConfig)
+	container.Register("config", ".toml", ParseTOMLConfig)
+	container.Register("config", ".yaml", ParseYAMLConfig)
+	container.Register("config", ".yml", ParseYAMLConfig)
+	container.Register("config", ".ini", ParseINIConfig)
+	container.Register("config", ".cfg", ParseINIConfig)
+	container.Register("config", ".conf", ParseINIConfig)
 
 	// raise an exception if the conflict handler is invalid
 	container.GetHandler()
@@ -103,8 +133,41 @@ func NewActionsContainer(
 	return container
 }
 
+// identity is the default type conversion, it returns the arg string unchanged.
+func identity(argString string) (any, error) {
+	return argString, nil
+}
+
+// intType converts the arg string to an int, registered as the "int" type.
+func intType(argString string) (any, error) {
+	return strconv.Atoi(strings.TrimSpace(argString))
+}
+
+// floatType converts the arg string to a float64, registered as the "float" type.
+func floatType(argString string) (any, error) {
+	return strconv.ParseFloat(strings.TrimSpace(argString), 64)
+}
+
+// newParsersAction creates a SubParsersAction for AddArgument, AddSubparsers
+// is usually called instead.
+func newParsersAction(argument *Argument) ActionInterface {
+	return NewSubParsersAction(NewAction(argument))
+}
+
 // Registration methods
 
+// RegisterAction registers factory as the action name, given as Action to
+// AddArgument. The built-in actions can be replaced.
+func (ac *ActionsContainer) RegisterAction(name string, factory ActionFactory) {
+	if name == "" {
+		panic("action name must not be empty")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("action %q has no factory", name))
+	}
+	ac.Register("action", name, factory)
+}
+
 // Register method to add a value to the registry in ActionsContainer
 func (ac *ActionsContainer) Register(registryName string, value any, object any) {
 	// Set default registry if it doesn't exist
@@ -205,12 +268,22 @@ func (ac *ActionsContainer) AddArgument(argument *Argument) ActionInterface {
 
 	// action := ac.createAction(actionName, argument)
 
-	createAction := ac.RegistryGet("action", actionName, actionName)
-	callback, ok := createAction.(func(*Argument) ActionInterface)
+	createAction := ac.RegistryGet("action", actionName, nil)
+	if createAction == nil {
+		panic(fmt.Sprintf("unknown action %q", actionName))
+	}
+	callback, ok := createAction.(ActionFactory)
 	if !ok {
-		panic(fmt.Sprintf("action %s, result does not implement ActionInterface: %T", actionName, callback))
+		panic(fmt.Sprintf("action %q is not an ActionFactory: %T", actionName, createAction))
 	}
 	action := callback(argument)
+	if action == nil || action.Struct() == nil {
+		panic(fmt.Sprintf("action %q created no action", actionName))
+	}
+	checkNargs(action.Struct())
+	if action.Struct().kind = actionName; actionName == "" {
+		action.Struct().kind = "store"
+	}
 
 	// raise an error if action for positional argument does not consume arguments
 	if action.Struct().OptionStrings == nil {
@@ -242,21 +315,55 @@ func (ac *ActionsContainer) AddArgument(argument *Argument) ActionInterface {
 	// }
 	ac.CheckHelp(action)
 
-	return ac.AddAction(action)
+	return ac.self().AddAction(action)
 }
 
-// FIXME: not done
-func (ac *ActionsContainer) AddArgumentGroup(argumentGroup ActionsContainerInterface) ActionsContainerInterface {
-	// group := NewArgumentGroup(
-	// 	a,
-	// 	kwargs["title"],
-	// 	kwargs["description"],
+// checkNargs panics if the Nargs of action is invalid or does not match its
+// Const, which requires OPTIONAL unless no argument is consumed.
+func checkNargs(action *Action) {
+	switch nargs := action.Nargs.(type) {
+	case nil:
+	case int:
+		if nargs < 0 {
+			panic(fmt.Sprintf("invalid nargs value: %d", nargs))
+		}
+	case string:
+		switch nargs {
+		case OPTIONAL, ZERO_OR_MORE, ONE_OR_MORE, REMAINDER, PARSER, SUPPRESS:
+		default:
+			panic(fmt.Sprintf("invalid nargs value: '%s'", nargs))
+		}
+	default:
+		panic(fmt.Sprintf("nargs must be an integer or a string literal (e.g., %s)", OPTIONAL))
+	}
+	if action.Const != nil && action.Nargs != OPTIONAL && action.Nargs != 0 {
+		panic(fmt.Sprintf("nargs must be '%s' to supply const", OPTIONAL))
+	}
+}
 
-	// )
+// self returns the parser or group embedding the container, or the
+// container itself.
+func (ac *ActionsContainer) self() ActionsContainerInterface {
+	if ac.Container != nil {
+		return ac.Container
+	}
+	return ac
+}
+
+// AddArgumentGroup adds a group created with NewArgumentGroup for the
+// container, its actions are listed in a section of the help.
+func (ac *ActionsContainer) AddArgumentGroup(argumentGroup ActionsContainerInterface) ActionsContainerInterface {
+	ac.ActionGroups = append(ac.ActionGroups, argumentGroup)
 	return argumentGroup
 }
 
+// AddMutuallyExclusiveGroup adds a group created with NewMutuallyExclusiveGroup,
+// its actions are added to the container.
 func (ac *ActionsContainer) AddMutuallyExclusiveGroup(mutuallyExclusiveGroup ActionsContainerInterface) ActionsContainerInterface {
+	if group, ok := mutuallyExclusiveGroup.(*MutuallyExclusiveGroup); ok {
+		group.bind(ac.self(), ac)
+	}
+	ac.MutuallyExclusiveGroups = append(ac.MutuallyExclusiveGroups, mutuallyExclusiveGroup)
 	return mutuallyExclusiveGroup
 }
 
@@ -266,7 +373,7 @@ func (ac *ActionsContainer) AddAction(action ActionInterface) ActionInterface {
 
 	// add to actions list
 	ac.Actions = append(ac.Actions, action)
-	action.Struct().Container = ac
+	action.Struct().Container = ac.self()
 
 	// index the action by any option strings it has
 	for _, optionString := range action.Struct().OptionStrings {
@@ -286,17 +393,14 @@ func (ac *ActionsContainer) AddAction(action ActionInterface) ActionInterface {
 }
 
 func (ac *ActionsContainer) RemoveAction(action ActionInterface) {
-	for i, v := range ac.Actions {
-		if v == action {
-			// Remove the item by slicing the array
-			ac.Actions = append(ac.Actions[:i], ac.Actions[i+1:]...)
-		}
-	}
+	ac.Actions = removeAction(ac.Actions, action)
 }
 
-// Not done
+// AddContainerAction adds the actions of container, e.g. a parent parser,
+// keeping their argument groups and mutually exclusive groups.
 func (ac *ActionsContainer) AddContainerAction(container ActionsContainerInterface) {
-	titleGroupMap := make(map[string]ActionsContainerInterface)
+	// collect groups by titles
+	titleGroupMap := make(map[string]*ArgumentGroup)
 	for _, groupInterface := range ac.ActionGroups {
 		group := groupInterface.(*ArgumentGroup)
 		if _, found := titleGroupMap[group.Title]; found {
@@ -305,14 +409,14 @@ func (ac *ActionsContainer) AddContainerAction(container ActionsContainerInterfa
 		titleGroupMap[group.Title] = group
 	}
 
-	//  map each action to its group
+	// map each action to its group
 	groupMap := make(map[ActionInterface]ActionsContainerInterface)
 	for _, groupInterface := range container.Struct().ActionGroups {
 		group := groupInterface.(*ArgumentGroup)
 		// if a group with the title exists, use that, otherwise
 		// create a new group matching the container's group
-		if _, found := titleGroupMap[group.Title]; found {
-			ac.AddArgumentGroup(
+		if _, found := titleGroupMap[group.Title]; !found {
+			titleGroupMap[group.Title] = ac.self().AddArgumentGroup(
 				NewArgumentGroup(
 					ac,
 					group.Title,
@@ -321,10 +425,11 @@ func (ac *ActionsContainer) AddContainerAction(container ActionsContainerInterfa
 					group.ConflictHandler,
 					nil,
 				),
-			)
+			).(*ArgumentGroup)
 		}
 
-		for _, action := range groupInterface.(*ArgumentGroup).GroupActions {
+		// map the actions to their new group
+		for _, action := range group.GroupActions {
 			groupMap[action] = titleGroupMap[group.Title]
 		}
 	}
@@ -332,27 +437,19 @@ func (ac *ActionsContainer) AddContainerAction(container ActionsContainerInterfa
 	// add container's mutually exclusive groups
 	// NOTE: if add_mutually_exclusive_group ever gains title= and
 	// description= then this code will need to be expanded as above
-	var cont ActionsContainerInterface
 	for _, groupInterface := range container.Struct().MutuallyExclusiveGroups {
 		group := groupInterface.(*MutuallyExclusiveGroup)
-		if group.ActionsContainer == container {
-			cont = ac
-		} else {
-			cont = titleGroupMap[group.ActionsContainer.Title]
+		var cont ActionsContainerInterface = ac.self()
+		if argumentGroup, ok := group.container.(*ArgumentGroup); ok {
+			cont = titleGroupMap[argumentGroup.Title]
 		}
-		mutexGroup := cont.AddMutuallyExclusiveGroup(
-			&MutuallyExclusiveGroup{
-				ActionsContainer: &ActionsContainer{
-					Required: group.Required,
-				},
-			},
-		)
+		mutexGroup := NewMutuallyExclusiveGroup("", "", nil, nil)
+		mutexGroup.Struct().Required = group.Required
+		cont.AddMutuallyExclusiveGroup(mutexGroup)
 
 		// map the actions to their new mutex group
 		for _, action := range group.GroupActions {
-			if _, found := groupMap[action]; found {
-				groupMap[action] = mutexGroup
-			}
+			groupMap[action] = mutexGroup
 		}
 	}
 
@@ -361,7 +458,7 @@ func (ac *ActionsContainer) AddContainerAction(container ActionsContainerInterfa
 		if group, found := groupMap[action]; found {
 			group.AddAction(action)
 		} else {
-			ac.AddAction(action)
+			ac.self().AddAction(action)
 		}
 	}
 }
@@ -381,10 +478,13 @@ func (ac *ActionsContainer) GetPositionalArgument(argument *Argument) *Argument
 		if t == 0 { // `t` is the asserted int value
 			panic("nargs for positionals must be != 0")
 		}
+		argument.Required = true
 	case string:
 		if t != OPTIONAL && t != ZERO_OR_MORE && t != REMAINDER && t != SUPPRESS {
 			argument.Required = true
 		}
+	case nil:
+		argument.Required = true
 	}
 
 	// return the keyword arguments with no option strings
@@ -414,7 +514,7 @@ func (ac *ActionsContainer) GetOptionalArgument(argument *Argument) *Argument {
 	var destOptionString string
 
 	if dest == "" {
-		if len(longOptionStrings) > 1 {
+		if len(longOptionStrings) > 0 {
 			destOptionString = longOptionStrings[0]
 		} else {
 			destOptionString = optionStrings[0]
@@ -501,7 +601,7 @@ func (ac *ActionsContainer) HandleConflictResolve(action ActionInterface, confli
 				// if the option now has no option string, remove it from the
 				// container holding it
 				if len(action.Struct().OptionStrings) == 0 {
-					action.Struct().Container.(*ActionsContainer).RemoveAction(action)
+					action.Struct().Container.RemoveAction(action)
 				}
 			}
 		}
@@ -517,7 +617,7 @@ func (ac *ActionsContainer) CheckHelp(action ActionInterface) {
 	// }
 }
 
-func (ac *ActionsContainer) GetFormatter_() any {
+func (ac *ActionsContainer) GetFormatter_() HelpFormatterInterface {
 	// Abstract method
 	return nil
 }
@@ -42,6 +42,13 @@ func NewAppendAction(argument *Argument) ActionInterface {
 			Help:          argument.Help,
 			MetaVar:       argument.MetaVar,
 			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
+			Prompt:        argument.Prompt,
+			PromptHidden:  argument.PromptHidden,
+			Sensitive:     argument.Sensitive,
+			Completer:     argument.Completer,
+			Validate:      argument.Validate,
+			Separator:     argument.Separator,
 		},
 	}
 }
@@ -52,7 +59,12 @@ func (a *AppendAction) Call(parser *ArgumentParser, namespace *Namespace, values
 		items = []any{}
 	}
 	items = CopyItems(items)
-	items = append(items.([]any), values)
+	if separated, ok := values.([]any); ok && a.Separator != "" {
+		// the items of separated arguments are appended one by one
+		items = append(items.([]any), separated...)
+	} else {
+		items = append(items.([]any), values)
+	}
 	namespace.Set(a.Dest, items)
 	return nil
 }
@@ -18,6 +18,7 @@ func NewAppendConstAction(argument *Argument) ActionInterface {
 			Help:          argument.Help,
 			MetaVar:       argument.MetaVar,
 			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
 		},
 	}
 }
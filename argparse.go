@@ -1,5 +1,10 @@
 package argparse
 
+import (
+	"os"
+	"path/filepath"
+)
+
 const (
 	SUPPRESS               = "==SUPPRESS=="
 	OPTIONAL               = "?"
@@ -10,6 +15,13 @@ const (
 	UNRECOGNIZED_ARGS_ATTR = "_unrecognized_args"
 )
 
+// ProgName returns prog if it is set, otherwise the base name of the running program.
 func ProgName(prog string) string {
-	return ""
+	if prog != "" {
+		return prog
+	}
+	if len(os.Args) == 0 {
+		return ""
+	}
+	return filepath.Base(os.Args[0])
 }
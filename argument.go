@@ -3,17 +3,27 @@ package argparse
 // Adding argument actions
 
 type Argument struct {
-	OptionStrings []string // The command-line option strings
-	Dest          string   // The destination name where the value will be stored
-	Nargs         any      // The number of arguments to consume
-	Const         any      // The constant value for certain actions
-	Default       any      // The default value if the option is not specified
-	Type          Type     // The function to convert the string to the appropriate type
-	Choices       []any    // The valid values for this argument
-	Required      bool     // Whether the argument is required
-	Help          string   // The help description for the argument
-	MetaVar       any      // The name to be used in help output
-	Deprecated    bool     // Whether the argument is deprecated
-	Action        string
-	Version       string
+	OptionStrings  []string // The command-line option strings
+	Dest           string   // The destination name where the value will be stored
+	Nargs          any      // The number of arguments to consume
+	Const          any      // The constant value for certain actions
+	Default        any      // The default value if the option is not specified
+	Type           Type     // The function to convert the string to the appropriate type
+	Choices        []any    // The valid values for this argument
+	Required       bool     // Whether the argument is required
+	Help           string   // The help description for the argument
+	MetaVar        any      // The name to be used in help output
+	Deprecated     bool     // Whether the argument is deprecated
+	Action         string
+	Version        string        // The version printed by the version action, with %(prog)s specifiers
+	Completer      CompleterFunc // The function completing the argument values in the shell
+	Validate       ValidateFunc  // The function validating the converted values, e.g. Range(1, 10)
+	KeySeparator   string        // The separator of the keys and values of map actions, "=" by default
+	NegationPrefix string        // The prefix of the negative options of boolean_optional actions, "no-" by default
+	Separator      string        // The separator splitting each argument of store, append and extend actions into a list, e.g. ","
+	Prompt         string        // The question asked for the argument if it is missing and stdin is a terminal
+	PromptHidden   bool          // Whether the answer to the prompt is not echoed, e.g. for passwords
+	Sensitive      bool          // Whether the value is a secret, masked in Repr, the encodings, the help and the errors
+	DuplicateKeys  string        // How map actions handle a key given several times, one of the DUPLICATE_KEYS_ constants
+	Env            string        // The environment variable used when the option is absent, SUPPRESS disables the parser's EnvPrefix
 }
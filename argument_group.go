@@ -2,12 +2,16 @@ package argparse
 
 import "fmt"
 
+// ArgumentGroup is a titled section of the help, its actions are the actions
+// of the container it was created for.
 type ArgumentGroup struct {
 	*ActionsContainer
 	Title           string
 	Description     string
 	ConflictHandler any
 	GroupActions    []ActionInterface
+
+	container *ActionsContainer // The container holding the actions of the group
 }
 
 func NewArgumentGroup(
@@ -26,49 +30,70 @@ func NewArgumentGroup(
 	// kwargs["conflictHandler"] = container.ConflictHandler
 	// kwargs["prefixChars"] = container.PrefixChars
 	// kwargs["argumentDefault"] = container.ArgumentDefault
+	if conflictHandler == nil {
+		conflictHandler = container.ConflictHandler
+	}
 
 	action := NewActionsContainer(
 		description,
 		container.PrefixChars,
 		container.ArgumentDefault,
-		container.ConflictHandler,
+		conflictHandler,
 	)
 
 	group := &ArgumentGroup{
 		ActionsContainer: action.(*ActionsContainer),
 		Title:            title,
+		Description:      description,
+		ConflictHandler:  conflictHandler,
 		GroupActions:     []ActionInterface{},
+		container:        container,
 	}
+	group.Container = group
 
+	// share most attributes with the container
 	group.Registries = container.Registries
-	group.Actions = container.Actions
 	group.OptionStringActions = container.OptionStringActions
 	group.Defaults = container.Defaults
 	group.HasNegativeNumberOptionals = container.HasNegativeNumberOptionals
-	group.MutuallyExclusiveGroups = container.MutuallyExclusiveGroups
 
 	return group
 }
 
+// AddAction adds action to the container of the group and to the group.
 func (ag *ArgumentGroup) AddAction(action ActionInterface) ActionInterface {
-	act := ag.ActionsContainer.AddAction(action)
+	act := ag.container.AddAction(action)
+	act.Struct().Container = ag
 	ag.GroupActions = append(ag.GroupActions, act)
 	return act
 }
 
-func (ac *ArgumentGroup) RemoveAction(action ActionInterface) {
-	for i, v := range ac.Actions {
-		if v == action {
-			// Remove the item by slicing the array
-			ac.Actions = append(ac.Actions[:i], ac.Actions[i+1:]...)
-		}
-	}
+// RemoveAction removes action from the container of the group and from the group.
+func (ag *ArgumentGroup) RemoveAction(action ActionInterface) {
+	ag.container.RemoveAction(action)
+	ag.GroupActions = removeAction(ag.GroupActions, action)
 }
 
 func (ag *ArgumentGroup) AddArgumentGroup(argumentGroup ActionsContainerInterface) ActionsContainerInterface {
 	panic("argument group can not be nested")
 }
 
-func (a *ArgumentGroup) AddMutuallyExclusiveGroup(mutuallyExclusiveGroup ActionsContainerInterface) ActionsContainerInterface {
-	panic("mutually exclusive groups cannot be nested")
+// AddMutuallyExclusiveGroup adds a mutually exclusive group whose actions
+// are added to this group.
+func (ag *ArgumentGroup) AddMutuallyExclusiveGroup(mutuallyExclusiveGroup ActionsContainerInterface) ActionsContainerInterface {
+	if group, ok := mutuallyExclusiveGroup.(*MutuallyExclusiveGroup); ok {
+		group.bind(ag, ag.container)
+	}
+	ag.container.MutuallyExclusiveGroups = append(ag.container.MutuallyExclusiveGroups, mutuallyExclusiveGroup)
+	return mutuallyExclusiveGroup
+}
+
+// removeAction returns actions without action.
+func removeAction(actions []ActionInterface, action ActionInterface) []ActionInterface {
+	for i, v := range actions {
+		if v == action {
+			return append(actions[:i:i], actions[i+1:]...)
+		}
+	}
+	return actions
 }
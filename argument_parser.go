@@ -1,20 +1,226 @@
 package argparse
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
 )
 
 type ArgumentParser struct {
 	*ActionsContainer
 	*AttributeHolder_
+
+	Prog                string    // The name of the program
+	Usage               string    // The string describing the program usage
+	Epilog              string    // Text following the argument descriptions
+	FormatterClass      any       // HelpFormatter class for printing help messages
+	FromfilePrefixChars string    // Characters that prefix files containing additional arguments
+	AddHelp             bool      // Add a -h/--help option
+	AllowAbbrev         bool      // Allow long options to be abbreviated unambiguously
+	ExitOnError         bool      // Determines whether or not ArgumentParser exits with error info when an error occurs
+	EnvPrefix           string    // Prefix of the environment variables of the optionals, e.g. MYTOOL_
+	ConfigFiles         []string  // Configuration files loaded if they exist and no config option is given
+	Prompter            Prompter  // Asks for the missing arguments with a Prompt, a TerminalPrompter on Stdin by default
+	Stdout              io.Writer // Where the help, the usage and the version are printed, os.Stdout by default
+	Stderr              io.Writer // Where the errors and the warnings are printed, os.Stderr by default
+	Stdin               io.Reader // Read for the FileType argument "-", os.Stdin by default
+
+	// The hooks, an error aborts parsing. The hooks of a parser apply to its subparsers too.
+	BeforeParse func(args []string) ([]string, error)                               // Rewrites the arguments before they are parsed
+	OnAction    func(action ActionInterface, values any, optionString string) error // Called after each action is taken, from any source
+	AfterParse  func(namespace *Namespace) error                                    // Normalizes the namespace once it is parsed
+
+	Subparsers   *SubParsersAction // The subparsers action, if AddSubparsers was called
+	Positionals_ *ArgumentGroup    // The "positional arguments" group
+	Optionals_   *ArgumentGroup    // The "options" group
+	Constraints  []*Constraint     // The constraints between arguments checked after parsing
+
+	inheritedConfig []ConfigEntry            // The entries of the parent's configuration section of this subparser
+	parent          *ArgumentParser          // The parser taking the subparsers action of this subparser, while it parses
+	exitHook        func(status int)         // Replaces os.Exit for this parser and its subparsers, e.g. in a REPL
+	valueSetters    []func(*Namespace) error // Set the values of the handles returned by Add after parsing
 }
 
 type NewArgumentParserFunc = func(kwargs map[string]any) (*ArgumentParser, error)
 
-func NewArgumentParser(kwargs map[string]any) ActionsContainerInterface {
-	return &ArgumentParser{
-		ActionsContainer: &ActionsContainer{},
+// argumentParserKwargs lists the keyword arguments accepted by NewArgumentParser.
+var argumentParserKwargs = []string{
+	"prog",
+	"usage",
+	"description",
+	"epilog",
+	"formatterClass",
+	"prefixChars",
+	"fromfilePrefixChars",
+	"argumentDefault",
+	"conflictHandler",
+	"addHelp",
+	"allowAbbrev",
+	"exitOnError",
+	"envPrefix",
+	"configFiles",
+	"parents",
+	"prompter",
+	"stdout",
+	"stderr",
+	"stdin",
+	"beforeParse",
+	"onAction",
+	"afterParse",
+}
+
+// kwarg returns kwargs[key] asserted to T, or defaultVal if the key is not present.
+func kwarg[T any](kwargs map[string]any, key string, defaultVal T) (T, error) {
+	value, found := kwargs[key]
+	if !found || value == nil {
+		return defaultVal, nil
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return defaultVal, fmt.Errorf("wrong %s type: %T", key, value)
+	}
+	return typed, nil
+}
+
+// NewArgumentParser creates a new ArgumentParser from Python-style keyword arguments,
+// see argumentParserKwargs for the accepted keys.
+func NewArgumentParser(kwargs map[string]any) (*ArgumentParser, error) {
+	for key := range kwargs {
+		if !containsString(argumentParserKwargs, key) {
+			return nil, fmt.Errorf("unexpected keyword argument: %s", key)
+		}
+	}
+
+	var err error
+	var description string
+	var prefixChars string
+	ap := &ArgumentParser{}
+
+	if ap.Prog, err = kwarg(kwargs, "prog", ""); err != nil {
+		return nil, err
+	}
+	if ap.Usage, err = kwarg(kwargs, "usage", ""); err != nil {
+		return nil, err
+	}
+	if description, err = kwarg(kwargs, "description", ""); err != nil {
+		return nil, err
+	}
+	if ap.Epilog, err = kwarg(kwargs, "epilog", ""); err != nil {
+		return nil, err
+	}
+	if ap.FormatterClass, err = kwarg[any](kwargs, "formatterClass", nil); err != nil {
+		return nil, err
+	}
+	if prefixChars, err = kwarg(kwargs, "prefixChars", "-"); err != nil {
+		return nil, err
+	}
+	if ap.FromfilePrefixChars, err = kwarg(kwargs, "fromfilePrefixChars", ""); err != nil {
+		return nil, err
+	}
+	if ap.AddHelp, err = kwarg(kwargs, "addHelp", true); err != nil {
+		return nil, err
+	}
+	if ap.AllowAbbrev, err = kwarg(kwargs, "allowAbbrev", true); err != nil {
+		return nil, err
+	}
+	if ap.ExitOnError, err = kwarg(kwargs, "exitOnError", true); err != nil {
+		return nil, err
+	}
+	if ap.EnvPrefix, err = kwarg(kwargs, "envPrefix", ""); err != nil {
+		return nil, err
+	}
+	if ap.ConfigFiles, err = kwarg[[]string](kwargs, "configFiles", nil); err != nil {
+		return nil, err
+	}
+	if ap.Stdout, err = kwarg[io.Writer](kwargs, "stdout", os.Stdout); err != nil {
+		return nil, err
+	}
+	if ap.Stderr, err = kwarg[io.Writer](kwargs, "stderr", os.Stderr); err != nil {
+		return nil, err
+	}
+	if ap.Stdin, err = kwarg[io.Reader](kwargs, "stdin", os.Stdin); err != nil {
+		return nil, err
+	}
+	if ap.Prompter, err = kwarg[Prompter](kwargs, "prompter", NewTerminalPrompter(ap.Stdin, ap.Stderr)); err != nil {
+		return nil, err
+	}
+	if ap.BeforeParse, err = kwarg[func([]string) ([]string, error)](kwargs, "beforeParse", nil); err != nil {
+		return nil, err
+	}
+	if ap.OnAction, err = kwarg[func(ActionInterface, any, string) error](kwargs, "onAction", nil); err != nil {
+		return nil, err
+	}
+	if ap.AfterParse, err = kwarg[func(*Namespace) error](kwargs, "afterParse", nil); err != nil {
+		return nil, err
+	}
+
+	ap.ActionsContainer = NewActionsContainer(
+		description,
+		prefixChars,
+		kwargs["argumentDefault"],
+		kwargs["conflictHandler"],
+	).(*ActionsContainer)
+	ap.Container = ap
+	ap.Prog = ProgName(ap.Prog)
+
+	// default argument groups
+	ap.Positionals_ = ap.AddArgumentGroup(NewArgumentGroup(ap.ActionsContainer, "positional arguments", "", "", nil, nil)).(*ArgumentGroup)
+	ap.Optionals_ = ap.AddArgumentGroup(NewArgumentGroup(ap.ActionsContainer, "options", "", "", nil, nil)).(*ArgumentGroup)
+
+	// add help argument if necessary
+	// (using explicit default to override global argument_default)
+	defaultPrefix := "-"
+	if !strings.Contains(prefixChars, "-") {
+		defaultPrefix = prefixChars[:1]
+	}
+	if ap.AddHelp {
+		ap.AddArgument(&Argument{
+			OptionStrings: []string{defaultPrefix + "h", strings.Repeat(defaultPrefix, 2) + "help"},
+			Action:        "help",
+			Default:       SUPPRESS,
+			Help:          "show this help message and exit",
+		})
+	}
+
+	// add parent arguments and defaults
+	parents, err := kwarg[[]*ArgumentParser](kwargs, "parents", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, parent := range parents {
+		ap.AddContainerAction(parent)
+		for key, value := range parent.Defaults {
+			ap.Defaults[key] = value
+		}
+	}
+
+	return ap, nil
+}
+
+// AddAction adds action to the positionals or the options group.
+func (ap *ArgumentParser) AddAction(action ActionInterface) ActionInterface {
+	if ap.Optionals_ == nil || ap.Positionals_ == nil {
+		return ap.ActionsContainer.AddAction(action)
+	}
+	if len(action.Struct().OptionStrings) > 0 {
+		return ap.Optionals_.AddAction(action)
 	}
+	return ap.Positionals_.AddAction(action)
+}
+
+// containsString reports whether value is present in slice.
+func containsString(slice []string, value string) bool {
+	for _, v := range slice {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 // Pretty __repr__ methods
@@ -25,108 +231,1418 @@ func (ap *ArgumentParser) GetMap() {
 
 // Optional/Positional adding methods
 
-func (ap *ArgumentParser) AddSubparsers(kwargs map[string]any) {
+// AddSubparsers adds the subparsers action, accepted kwargs are title, description,
+// prog, dest, required, help, metavar, parserClass and pluginPrefix.
+func (ap *ArgumentParser) AddSubparsers(kwargs map[string]any) (*SubParsersAction, error) {
+	if ap.Subparsers != nil {
+		return nil, NewArgumentError(nil, "cannot have multiple subparser arguments")
+	}
 
-}
+	var err error
+	argument := &Argument{}
+	var title, description, prog, pluginPrefix string
+	parserClass := NewArgumentParser
 
-func (ap *ArgumentParser) AddAction(action ActionInterface) ActionInterface {
-	return &Action{}
+	if title, err = kwarg(kwargs, "title", ""); err != nil {
+		return nil, err
+	}
+	if description, err = kwarg(kwargs, "description", ""); err != nil {
+		return nil, err
+	}
+
+	if prog, err = kwarg(kwargs, "prog", ""); err != nil {
+		return nil, err
+	}
+	if argument.Dest, err = kwarg(kwargs, "dest", SUPPRESS); err != nil {
+		return nil, err
+	}
+	if argument.Required, err = kwarg(kwargs, "required", false); err != nil {
+		return nil, err
+	}
+	if argument.Help, err = kwarg(kwargs, "help", ""); err != nil {
+		return nil, err
+	}
+	if argument.MetaVar, err = kwarg[any](kwargs, "metavar", nil); err != nil {
+		return nil, err
+	}
+	if parserClass, err = kwarg(kwargs, "parserClass", parserClass); err != nil {
+		return nil, err
+	}
+	if pluginPrefix, err = kwarg(kwargs, "pluginPrefix", ""); err != nil {
+		return nil, err
+	}
+
+	if prog == "" {
+		prog = ap.Prog
+		for _, action := range ap.GetPositionalActions() {
+			if usage := action.Struct().Dest; usage != SUPPRESS {
+				prog += " " + usage
+			}
+		}
+	}
+
+	action := NewSubParsersAction(&Action{
+		OptionStrings: []string{},
+		Dest:          argument.Dest,
+		Required:      argument.Required,
+		Help:          argument.Help,
+		MetaVar:       argument.MetaVar,
+	})
+	action.ProgPrefix = prog
+	action.kind = "parsers"
+	action.ParserClass = parserClass
+	action.ExitOnError = ap.ExitOnError
+	action.owner = ap
+	if pluginPrefix != "" {
+		action.EnablePlugins(pluginPrefix)
+	}
+
+	// the subparsers have their own group if they have a title or description
+	if title != "" || description != "" {
+		if title == "" {
+			title = "subcommands"
+		}
+		group := ap.AddArgumentGroup(NewArgumentGroup(ap.ActionsContainer, title, description, "", nil, nil))
+		group.AddAction(action)
+	} else {
+		ap.AddAction(action)
+	}
+	ap.Subparsers = action
+	return action, nil
 }
 
+// GetOptionalActions returns the actions that have option strings.
 func (ap *ArgumentParser) GetOptionalActions() []ActionInterface {
-	return []ActionInterface{}
+	actions := []ActionInterface{}
+	for _, action := range ap.Actions {
+		if len(action.Struct().OptionStrings) > 0 {
+			actions = append(actions, action)
+		}
+	}
+	return actions
 }
 
+// GetPositionalActions returns the actions that have no option strings.
 func (ap *ArgumentParser) GetPositionalActions() []ActionInterface {
-	return []ActionInterface{}
+	actions := []ActionInterface{}
+	for _, action := range ap.Actions {
+		if len(action.Struct().OptionStrings) == 0 {
+			actions = append(actions, action)
+		}
+	}
+	return actions
 }
 
 // Command line argument parsing methods
 
-func (ap *ArgumentParser) ParseArgs(args []any, namespace *Namespace) []any {
-	return []any{}
+// OptionTuple is a possible interpretation of an option string: the matched
+// action (nil if there is no such option), the option string, the separator
+// and the explicit argument (nil if there is none).
+type OptionTuple struct {
+	Action       ActionInterface
+	OptionString string
+	Sep          string
+	ExplicitArg  *string
 }
 
-func (ap *ArgumentParser) ParseKnownArgs(args []any, namespace *Namespace) []any {
-	return []any{}
+// ParseArgs parses args, os.Args[1:] if nil, into namespace and reports
+// unrecognized arguments as an error.
+func (ap *ArgumentParser) ParseArgs(args []string, namespace *Namespace) (*Namespace, error) {
+	namespace, argv, err := ap.ParseKnownArgs(args, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(argv) > 0 {
+		msg := fmt.Sprintf("unrecognized arguments: %s", strings.Join(argv, " "))
+		if ap.ExitOnError {
+			ap.Error(msg)
+		}
+		return nil, NewArgumentError(nil, msg)
+	}
+	ap.checkPrintConfig(namespace)
+	return namespace, nil
 }
 
-func (ap *ArgumentParser) ParseKnownArgs2(args []any, namespace *Namespace, intermixed any) {
+// checkPrintConfig prints namespace and exits if a PrintConfigAction was taken.
+func (ap *ArgumentParser) checkPrintConfig(namespace *Namespace) {
+	if namespace.printConfig {
+		namespace.printConfig = false
+		ap.PrintConfig(namespace, nil)
+		ap.Exit(0, "")
+	}
+}
 
+// ParseKnownArgs works like ParseArgs, except that it returns the
+// unrecognized arguments instead of reporting them as an error.
+func (ap *ArgumentParser) ParseKnownArgs(args []string, namespace *Namespace) (*Namespace, []string, error) {
+	return ap.ParseKnownArgs2(args, namespace, false)
 }
 
-func (ap *ArgumentParser) ParseKnownArgs_(args []any, namespace *Namespace, intermixed any) {
+func (ap *ArgumentParser) ParseKnownArgs2(args []string, namespace *Namespace, intermixed bool) (*Namespace, []string, error) {
+	if args == nil {
+		args = os.Args[1:]
+	} else {
+		args = append([]string{}, args...)
+	}
+
+	// let the hook rewrite the arguments
+	if ap.BeforeParse != nil {
+		var err error
+		if args, err = ap.BeforeParse(args); err != nil {
+			if ap.ExitOnError {
+				ap.Error(err.Error())
+			}
+			return nil, nil, err
+		}
+	}
+
+	// the hidden completion entrypoint, used by the dynamic completion scripts
+	if shell := os.Getenv(COMPLETE_ENV); shell != "" {
+		ap.printMessage(FormatCandidates(ap.Complete(args), shell), ap.stdout())
+		ap.Exit(0, "")
+	}
+
+	// make sure that args are mutable
+	if namespace == nil {
+		namespace = NewNamespace(map[string]any{})
+	}
+	ap.setNamespaceDefaults(namespace)
+
+	// parse the arguments and exit if there are any errors, a version
+	// requested before the error is printed instead
+	parsed, args, err := ap.ParseKnownArgs_(args, namespace, intermixed)
+	if err != nil {
+		ap.checkPrintVersion(namespace)
+		if ap.ExitOnError {
+			ap.Error(err.Error())
+		}
+		return nil, nil, err
+	}
+	namespace = parsed
+
+	if unrecognized, found := namespace.Get(UNRECOGNIZED_ARGS_ATTR); found {
+		args = append(args, unrecognized.([]string)...)
+		delete(namespace.attributes, UNRECOGNIZED_ARGS_ATTR)
+	}
+
+	// let the hook normalize the namespace
+	if ap.AfterParse != nil {
+		if err := ap.AfterParse(namespace); err != nil {
+			if ap.ExitOnError {
+				ap.Error(err.Error())
+			}
+			return nil, nil, err
+		}
+	}
+
+	// set the values of the typed handles
+	for _, setValue := range ap.valueSetters {
+		if err := setValue(namespace); err != nil {
+			if ap.ExitOnError {
+				ap.Error(err.Error())
+			}
+			return nil, nil, err
+		}
+	}
 
+	// run the plugin subcommand, exiting with its status
+	if err := ap.runPlugin(namespace); err != nil {
+		if ap.ExitOnError {
+			ap.Error(err.Error())
+		}
+		return nil, nil, err
+	}
+	return namespace, args, nil
 }
 
-func (ap *ArgumentParser) ReadArgsFromFiles_(argString string) {
+// setNamespaceDefaults adds the action and parser defaults that are not present in namespace,
+// and marks the dests of the sensitive actions.
+func (ap *ArgumentParser) setNamespaceDefaults(namespace *Namespace) {
+	// add any action defaults that aren't present
+	for _, action := range ap.Actions {
+		dest := action.Struct().Dest
+		if action.Struct().Sensitive && dest != SUPPRESS {
+			namespace.MarkSensitive(dest)
+		}
+		if dest != SUPPRESS && !namespace.Contains(dest) && action.Struct().Default != SUPPRESS {
+			namespace.Set(dest, action.Struct().Default)
+			namespace.setSource(dest, Source{Kind: SOURCE_DEFAULT})
+		}
+	}
 
+	// add any parser defaults that aren't present
+	for dest, value := range ap.Defaults {
+		if !namespace.Contains(dest) {
+			namespace.Set(dest, value)
+			namespace.setSource(dest, Source{Kind: SOURCE_PARSER_DEFAULT})
+		}
+	}
 }
 
-func (ap *ArgumentParser) ConvertArgLineToArgs_(argString string) []any {
-	return []any{argString}
+func (ap *ArgumentParser) ParseKnownArgs_(argStrings []string, namespace *Namespace, intermixed bool) (*Namespace, []string, error) {
+	// replace arg strings that are file references
+	if ap.FromfilePrefixChars != "" {
+		var err error
+		if argStrings, err = ap.ReadArgsFromFiles_(argStrings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// the indices of the sources are relative to the arguments of the
+	// parent parser's action for subparsers
+	argOffset := namespace.argIndex
+	defer func() { namespace.argIndex = argOffset }()
+
+	// read the configuration files, before parsing so that the sections
+	// of the subcommands are passed on to the subparsers
+	config, err := ap.LoadConfig_(argStrings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// map all mutually exclusive arguments to the other arguments
+	// they can't occur with
+	actionConflicts := make(map[ActionInterface][]ActionInterface)
+	for _, groupInterface := range ap.MutuallyExclusiveGroups {
+		mutexGroup, ok := groupInterface.(*MutuallyExclusiveGroup)
+		if !ok {
+			continue
+		}
+		groupActions := mutexGroup.GroupActions
+		for i, mutexAction := range groupActions {
+			conflicts := actionConflicts[mutexAction]
+			conflicts = append(conflicts, groupActions[:i]...)
+			conflicts = append(conflicts, groupActions[i+1:]...)
+			actionConflicts[mutexAction] = conflicts
+		}
+	}
+
+	// find all option indices, and determine the arg_string_pattern
+	// which has an 'O' if there is an option at an index,
+	// an 'A' if there is an argument, or a '-' if there is a '--'
+	optionStringIndices := make(map[int][]OptionTuple)
+	var argStringPatternParts []string
+	for i := 0; i < len(argStrings); i++ {
+		argString := argStrings[i]
+
+		// all args after -- are non-options
+		if argString == "--" {
+			argStringPatternParts = append(argStringPatternParts, "-")
+			for i++; i < len(argStrings); i++ {
+				argStringPatternParts = append(argStringPatternParts, "A")
+			}
+			break
+		}
+
+		// otherwise, add the arg to the arg strings
+		// and note the index if it was an option
+		optionTuples, err := ap.ParseOptional_(argString)
+		if err != nil {
+			return nil, nil, err
+		}
+		if optionTuples == nil {
+			argStringPatternParts = append(argStringPatternParts, "A")
+		} else {
+			optionStringIndices[i] = optionTuples
+			argStringPatternParts = append(argStringPatternParts, "O")
+		}
+	}
+
+	// join the pieces together to form the pattern
+	argStringsPattern := strings.Join(argStringPatternParts, "")
+
+	// converts arg strings to the appropriate and then takes the action
+	seenActions := make(map[ActionInterface]bool)
+	seenNonDefaultActions := make(map[ActionInterface]bool)
+	warned := make(map[string]bool)
+
+	// takes the action with the values converted from argumentStrings
+	takeValues := func(action ActionInterface, argumentValues any, argumentStrings []string, optionString string, source Source) error {
+		seenActions[action] = true
+
+		// error if this argument is not allowed with other previously
+		// seen arguments
+		if len(action.Struct().OptionStrings) > 0 || len(argumentStrings) > 0 {
+			seenNonDefaultActions[action] = true
+			for _, conflictAction := range actionConflicts[action] {
+				if seenNonDefaultActions[conflictAction] {
+					actionName := GetActionName(conflictAction.Struct())
+					return NewArgumentError(action.Struct(), fmt.Sprintf("not allowed with argument %s", actionName))
+				}
+			}
+		}
+
+		// take the action if we didn't receive a SUPPRESS value
+		// (e.g. from a default)
+		if value, ok := argumentValues.(string); !ok || value != SUPPRESS {
+			namespace.argIndex = source.Index
+			if err := action.Call(ap, namespace, argumentValues, optionString); err != nil {
+				return maskError(action, argumentStrings, err)
+			}
+
+			// positionals without arguments take their default
+			if len(action.Struct().OptionStrings) == 0 && len(argumentStrings) == 0 {
+				source = Source{Kind: SOURCE_DEFAULT}
+			}
+			if dest := action.Struct().Dest; dest != SUPPRESS && namespace.Contains(dest) {
+				namespace.setSource(dest, source)
+			}
+			if err := ap.onAction(action, argumentValues, optionString); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	takeAction := func(action ActionInterface, argumentStrings []string, optionString string, source Source) error {
+		argumentValues, err := ap.GetValues(action, argumentStrings)
+		if err != nil {
+			return maskError(action, argumentStrings, err)
+		}
+		return takeValues(action, argumentValues, argumentStrings, optionString, source)
+	}
+
+	var extras []string
+	var extrasPattern []string
+
+	// function to convert arg_strings into an optional action
+	consumeOptional := func(startIndex int) (int, error) {
+		// get the optional identified at this index
+		optionTuples := optionStringIndices[startIndex]
+
+		// if multiple actions match, the option string was ambiguous
+		if len(optionTuples) > 1 {
+			var options []string
+			for _, optionTuple := range optionTuples {
+				options = append(options, optionTuple.OptionString)
+			}
+			msg := fmt.Sprintf("ambiguous option: %s could match %s", argStrings[startIndex], strings.Join(options, ", "))
+			return 0, NewArgumentError(nil, msg)
+		}
+
+		optionTuple := optionTuples[0]
+		action := optionTuple.Action
+		optionString := optionTuple.OptionString
+		sep := optionTuple.Sep
+		explicitArg := optionTuple.ExplicitArg
+
+		type actionTuple struct {
+			action       ActionInterface
+			args         []string
+			optionString string
+		}
+
+		// identify additional optionals in the same arg string
+		// (e.g. -xyz is the same as -x -y -z if no args are required)
+		var actionTuples []actionTuple
+		var stop int
+		for {
+			// if we found no optional action, skip it
+			if action == nil {
+				extras = append(extras, argStrings[startIndex])
+				extrasPattern = append(extrasPattern, "O")
+				return startIndex + 1, nil
+			}
+
+			// if there is an explicit argument, try to match the
+			// optional's string arguments to only this
+			if explicitArg != nil {
+				argCount, err := ap.MatchArgument_(action, "A")
+				if err != nil {
+					return 0, err
+				}
+
+				// if the action is a single-dash option and takes no
+				// arguments, try to parse more single-dash options out
+				// of the tail of the option string
+				chars := ap.PrefixChars
+				if argCount == 0 && !strings.Contains(chars, optionString[1:2]) && *explicitArg != "" {
+					if sep != "" || strings.Contains(chars, (*explicitArg)[:1]) {
+						return 0, NewArgumentError(action.Struct(), fmt.Sprintf("ignored explicit argument '%s'", *explicitArg))
+					}
+					actionTuples = append(actionTuples, actionTuple{action, []string{}, optionString})
+					char := optionString[:1]
+					optionString = char + (*explicitArg)[:1]
+					if optionalAction, found := ap.OptionStringActions[optionString]; found {
+						action = optionalAction
+						rest := (*explicitArg)[1:]
+						if rest == "" {
+							sep = ""
+							explicitArg = nil
+						} else if rest[:1] == "=" {
+							sep = "="
+							rest = rest[1:]
+							explicitArg = &rest
+						} else {
+							sep = ""
+							explicitArg = &rest
+						}
+					} else {
+						extras = append(extras, char+*explicitArg)
+						extrasPattern = append(extrasPattern, "O")
+						stop = startIndex + 1
+						break
+					}
+				} else if argCount == 1 {
+					// if the action expect exactly one argument, we've
+					// successfully matched the option; exit the loop
+					stop = startIndex + 1
+					actionTuples = append(actionTuples, actionTuple{action, []string{*explicitArg}, optionString})
+					break
+				} else {
+					// error if a double-dash option did not use the
+					// explicit argument
+					return 0, NewArgumentError(action.Struct(), fmt.Sprintf("ignored explicit argument '%s'", *explicitArg))
+				}
+			} else {
+				// if there is no explicit argument, try to match the
+				// optional's string arguments with the following strings
+				// if successful, exit the loop
+				start := startIndex + 1
+				selectedPatterns := argStringsPattern[start:]
+				argCount, err := ap.MatchArgument_(action, selectedPatterns)
+				if err != nil {
+					return 0, err
+				}
+				stop = start + argCount
+				actionTuples = append(actionTuples, actionTuple{action, argStrings[start:stop], optionString})
+				break
+			}
+		}
+
+		// add the Optional to the list and return the index at which
+		// the Optional's string args stopped
+		for _, tuple := range actionTuples {
+			if tuple.action.Struct().Deprecated && !warned[tuple.optionString] {
+				ap.Warning(fmt.Sprintf("option '%s' is deprecated", tuple.optionString))
+				warned[tuple.optionString] = true
+			}
+			source := Source{Kind: SOURCE_COMMAND_LINE, Name: tuple.optionString, Index: argOffset + startIndex}
+			if err := takeAction(tuple.action, tuple.args, tuple.optionString, source); err != nil {
+				return 0, err
+			}
+		}
+		return stop, nil
+	}
+
+	// the list of Positionals left to be parsed; this is modified
+	// by consume_positionals()
+	positionals := ap.GetPositionalActions()
+
+	// function to convert arg_strings into positional actions
+	consumePositionals := func(startIndex int) (int, error) {
+		// match as many Positionals as possible
+		selectedPattern := argStringsPattern[startIndex:]
+		argCounts := ap.MatchArgumentsPartial_(positionals, selectedPattern)
+
+		// slice off the appropriate arg strings for each Positional
+		// and add the Positional and its args to the list
+		for i, argCount := range argCounts {
+			action := positionals[i]
+			args := append([]string{}, argStrings[startIndex:startIndex+argCount]...)
+			source := Source{Kind: SOURCE_COMMAND_LINE, Name: GetActionName(action.Struct()), Index: argOffset + startIndex}
+
+			// Strip out the first '--' if it is not in REMAINDER arg.
+			if action.Struct().Nargs == PARSER {
+				if argStringsPattern[startIndex] == '-' {
+					args = args[1:]
+				}
+			} else if action.Struct().Nargs != REMAINDER {
+				if strings.Contains(argStringsPattern[startIndex:startIndex+argCount], "-") {
+					args = removeString(args, "--")
+				}
+			}
+			startIndex += argCount
+			if len(args) > 0 && action.Struct().Deprecated && !warned[action.Struct().Dest] {
+				ap.Warning(fmt.Sprintf("argument '%s' is deprecated", action.Struct().Dest))
+				warned[action.Struct().Dest] = true
+			}
+			if err := takeAction(action, args, "", source); err != nil {
+				return 0, err
+			}
+		}
+
+		// slice off the Positionals that we just parsed and return the
+		// index at which the Positionals' string args stopped
+		positionals = positionals[len(argCounts):]
+		return startIndex, nil
+	}
+
+	// consume Positionals and Optionals alternately, until we have
+	// passed the last option string
+	startIndex := 0
+	maxOptionStringIndex := -1
+	for index := range optionStringIndices {
+		maxOptionStringIndex = max(maxOptionStringIndex, index)
+	}
+	for startIndex <= maxOptionStringIndex {
+		// consume any Positionals preceding the next option
+		nextOptionStringIndex := startIndex
+		for nextOptionStringIndex <= maxOptionStringIndex {
+			if _, found := optionStringIndices[nextOptionStringIndex]; found {
+				break
+			}
+			nextOptionStringIndex++
+		}
+		if !intermixed && startIndex != nextOptionStringIndex {
+			positionalsEndIndex, err := consumePositionals(startIndex)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// only try to parse the next optional if we didn't consume
+			// the option string during the positionals parsing
+			if positionalsEndIndex > startIndex {
+				startIndex = positionalsEndIndex
+				continue
+			}
+			startIndex = positionalsEndIndex
+		}
+
+		// if we consumed all the positionals we could and we're not
+		// at the index of an option string, there were extra arguments
+		if _, found := optionStringIndices[startIndex]; !found {
+			extras = append(extras, argStrings[startIndex:nextOptionStringIndex]...)
+			for _, c := range argStringsPattern[startIndex:nextOptionStringIndex] {
+				extrasPattern = append(extrasPattern, string(c))
+			}
+			startIndex = nextOptionStringIndex
+		}
+
+		// consume the next optional and any arguments for it
+		var err error
+		if startIndex, err = consumeOptional(startIndex); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if !intermixed {
+		// consume any positionals following the last Optional
+		stopIndex, err := consumePositionals(startIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// if we didn't consume all the argument strings, there were extras
+		extras = append(extras, argStrings[stopIndex:]...)
+	} else {
+		extras = append(extras, argStrings[startIndex:]...)
+		for _, c := range argStringsPattern[startIndex:] {
+			extrasPattern = append(extrasPattern, string(c))
+		}
+
+		// consume all positionals
+		argStrings = []string{}
+		for i, c := range extrasPattern {
+			if c != "O" {
+				argStrings = append(argStrings, extras[i])
+			}
+		}
+		argStringsPattern = strings.ReplaceAll(strings.Join(extrasPattern, ""), "O", "")
+		stopIndex, err := consumePositionals(0)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// leave unknown optionals and non-consumed positionals in extras
+		var remaining []string
+		for i, c := range extrasPattern {
+			if stopIndex > 0 && c != "O" {
+				stopIndex--
+				continue
+			}
+			remaining = append(remaining, extras[i])
+		}
+		extras = remaining
+	}
+
+	// print the version still waiting for a VersionJSONAction once all the
+	// command line arguments are consumed
+	ap.checkPrintVersion(namespace)
+
+	// the command line wins over the environment, the configuration files
+	// and the prompt for mutually exclusive arguments
+	conflicted := func(action ActionInterface) bool {
+		for _, conflictAction := range actionConflicts[action] {
+			if seenNonDefaultActions[conflictAction] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// take the actions absent from the command line from the environment
+	for _, action := range ap.Actions {
+		name := envName(action, ap.EnvPrefix)
+		if seenActions[action] || name == "" {
+			continue
+		}
+		value, found := os.LookupEnv(name)
+		if !found {
+			continue
+		}
+		if conflicted(action) {
+			continue
+		}
+
+		optionString := ""
+		if len(action.Struct().OptionStrings) > 0 {
+			optionString = action.Struct().OptionStrings[0]
+		}
+		argStrings, err := ap.EnvArgStrings_(action, value)
+		for _, args := range argStrings {
+			if err != nil {
+				break
+			}
+			err = takeAction(action, args, optionString, Source{Kind: SOURCE_ENV, Name: name})
+		}
+		if err != nil {
+			var argumentErr *ArgumentError
+			if errors.As(err, &argumentErr) {
+				argumentErr.Message += fmt.Sprintf(" (from environment variable %s)", name)
+			}
+			return nil, nil, err
+		}
+		seenActions[action] = true
+	}
+
+	// take the actions absent from the command line and the environment
+	// from the configuration files, the last value of a key wins
+	configActions := []ActionInterface{}
+	configEntries := make(map[ActionInterface]ConfigEntry)
+	for _, entry := range config {
+		action := ap.ConfigAction_(entry.Keys[0])
+		if action == nil {
+			// parser defaults are set as they are
+			if entry.Value != nil {
+				namespace.Set(entry.Keys[0], entry.Value)
+				namespace.setSource(entry.Keys[0], Source{Kind: SOURCE_CONFIG, Name: entry.File, Line: entry.Line})
+			}
+			continue
+		}
+		if _, found := configEntries[action]; !found {
+			configActions = append(configActions, action)
+		}
+		configEntries[action] = entry
+	}
+	for _, action := range configActions {
+		entry := configEntries[action]
+		if seenActions[action] || entry.Value == nil {
+			continue
+		}
+		if conflicted(action) {
+			continue
+		}
+
+		optionString := ""
+		if len(action.Struct().OptionStrings) > 0 {
+			optionString = action.Struct().OptionStrings[0]
+		}
+		argStrings, err := ap.ConfigArgStrings_(action, entry.Value)
+		for _, args := range argStrings {
+			if err != nil {
+				break
+			}
+			err = takeAction(action, args, optionString, Source{Kind: SOURCE_CONFIG, Name: entry.File, Line: entry.Line})
+		}
+		if err != nil {
+			var argumentErr *ArgumentError
+			if errors.As(err, &argumentErr) {
+				argumentErr.Message += fmt.Sprintf(" (from %s:%d)", entry.File, entry.Line)
+			}
+			return nil, nil, err
+		}
+		seenActions[action] = true
+	}
+
+	// ask for the missing arguments with a prompt if the user can answer,
+	// optional ones too since the Prompt is set per argument, e.g. for the
+	// confirmation of a flag; an empty answer keeps their default
+	for _, action := range ap.Actions {
+		if seenActions[action] || action.Struct().Prompt == "" || ap.Prompter == nil || !ap.Prompter.Interactive() {
+			continue
+		}
+		if conflicted(action) {
+			continue
+		}
+
+		optionString := ""
+		if len(action.Struct().OptionStrings) > 0 {
+			optionString = action.Struct().OptionStrings[0]
+		}
+		found, err := ap.PromptAction_(action, func(argStrings []string, values any) error {
+			return takeValues(action, values, argStrings, optionString, Source{Kind: SOURCE_PROMPT})
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		seenActions[action] = found
+	}
+
+	// make sure all required actions were present and also convert
+	// action defaults which were not given as arguments
+	var requiredActions []string
+	for _, action := range ap.Actions {
+		if seenActions[action] {
+			continue
+		}
+		act := action.Struct()
+		if act.Required {
+			requiredActions = append(requiredActions, GetActionName(act))
+		} else {
+			// Convert action default now instead of doing it before
+			// parsing arguments to avoid calling convert functions
+			// twice (which may fail) if the argument was given, but
+			// only if it was defined already in the namespace
+			defaultString, isString := act.Default.(string)
+			if value, found := namespace.Get(act.Dest); isString && found && value == act.Default {
+				converted, err := ap.GetValue(action, defaultString)
+				if err != nil {
+					return nil, nil, err
+				}
+				namespace.Set(act.Dest, converted)
+			}
+		}
+	}
+
+	// make sure all required groups had one option present
+	for _, groupInterface := range ap.MutuallyExclusiveGroups {
+		group, ok := groupInterface.(*MutuallyExclusiveGroup)
+		if !ok || !group.Required {
+			continue
+		}
+		used := false
+		for _, action := range group.GroupActions {
+			if seenNonDefaultActions[action] {
+				used = true
+				break
+			}
+		}
+
+		// if no actions were used, the group is required like an action
+		if !used {
+			var names []string
+			for _, action := range group.GroupActions {
+				if action.Struct().Help != SUPPRESS {
+					names = append(names, GetActionName(action.Struct()))
+				}
+			}
+			requiredActions = append(requiredActions, "("+strings.Join(names, " | ")+")")
+		}
+	}
+
+	if len(requiredActions) > 0 {
+		msg := fmt.Sprintf("the following arguments are required: %s", strings.Join(requiredActions, ", "))
+		return nil, nil, NewArgumentError(nil, msg)
+	}
+
+	// make sure the constraints between the arguments hold
+	present := func(action ActionInterface) bool { return seenNonDefaultActions[action] }
+	for _, constraint := range ap.Constraints {
+		if err := constraint.Check(namespace, present); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// return the updated namespace and the extra arguments
+	return namespace, extras, nil
 }
 
-func (ap *ArgumentParser) MatchArgument_(action ActionInterface, argStringsPattern string) {
+// onAction calls the OnAction hooks of the parser and of its parents.
+func (ap *ArgumentParser) onAction(action ActionInterface, values any, optionString string) error {
+	for parser := ap; parser != nil; parser = parser.parent {
+		if parser.OnAction != nil {
+			if err := parser.OnAction(action, values, optionString); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func (ap *ArgumentParser) MatchArgumentsPartial_(action ActionInterface, argStringsPattern string) {
+// removeString removes the first occurrence of value from slice.
+func removeString(slice []string, value string) []string {
+	for i, v := range slice {
+		if v == value {
+			return append(slice[:i:i], slice[i+1:]...)
+		}
+	}
+	return slice
+}
+
+// ReadArgsFromFiles_ replaces arguments starting with one of FromfilePrefixChars
+// by the arguments read from the named file, one per line.
+func (ap *ArgumentParser) ReadArgsFromFiles_(argStrings []string) ([]string, error) {
+	// expand arguments referencing files
+	newArgStrings := []string{}
+	for _, argString := range argStrings {
+		// for regular arguments, just add them back into the list
+		if argString == "" || !strings.Contains(ap.FromfilePrefixChars, argString[:1]) {
+			newArgStrings = append(newArgStrings, argString)
+			continue
+		}
+
+		// replace arguments referencing files with the file content
+		content, err := os.ReadFile(argString[1:])
+		if err != nil {
+			return nil, NewArgumentError(nil, err.Error())
+		}
+		fileArgStrings := []string{}
+		for _, argLine := range strings.Split(strings.TrimRight(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), "\n") {
+			fileArgStrings = append(fileArgStrings, ap.ConvertArgLineToArgs_(argLine)...)
+		}
+		fileArgStrings, err = ap.ReadArgsFromFiles_(fileArgStrings)
+		if err != nil {
+			return nil, err
+		}
+		newArgStrings = append(newArgStrings, fileArgStrings...)
+	}
+
+	// return the modified argument list
+	return newArgStrings, nil
 }
 
-func (ap *ArgumentParser) ParseOptional_(argString string) {
+func (ap *ArgumentParser) ConvertArgLineToArgs_(argLine string) []string {
+	return []string{argLine}
 }
 
-func (ap *ArgumentParser) GetOptionTuples_(optionString string) {
+// MatchArgument_ returns the number of arg strings consumed by action from
+// the start of argStringsPattern.
+func (ap *ArgumentParser) MatchArgument_(action ActionInterface, argStringsPattern string) (int, error) {
+	// match the pattern for this action to the arg strings
+	nargsPattern := regexp.MustCompile("^" + ap.GetNargsPattern_(action))
+	match := nargsPattern.FindStringSubmatch(argStringsPattern)
+
+	// raise an exception if we weren't able to find a match
+	if match == nil {
+		var msg string
+		switch nargs := action.Struct().Nargs.(type) {
+		case nil:
+			msg = "expected one argument"
+		case int:
+			if nargs == 1 {
+				msg = fmt.Sprintf("expected %d argument", nargs)
+			} else {
+				msg = fmt.Sprintf("expected %d arguments", nargs)
+			}
+		default:
+			switch nargs {
+			case OPTIONAL:
+				msg = "expected at most one argument"
+			case ONE_OR_MORE:
+				msg = "expected at least one argument"
+			default:
+				msg = fmt.Sprintf("expected %v arguments", nargs)
+			}
+		}
+		return 0, NewArgumentError(action.Struct(), msg)
+	}
+
+	// return the number of arguments matched
+	return len(match[1]), nil
 }
 
-func (ap *ArgumentParser) GetNargsPattern_(action ActionInterface) {
+// MatchArgumentsPartial_ returns the number of arg strings consumed by each
+// of as many actions as possible from the start of argStringsPattern.
+func (ap *ArgumentParser) MatchArgumentsPartial_(actions []ActionInterface, argStringsPattern string) []int {
+	// progressively shorten the actions list by slicing off the
+	// final actions until we find a match
+	for i := len(actions); i > 0; i-- {
+		var pattern strings.Builder
+		for _, action := range actions[:i] {
+			pattern.WriteString(ap.GetNargsPattern_(action))
+		}
+		match := regexp.MustCompile("^" + pattern.String()).FindStringSubmatchIndex(argStringsPattern)
+		if match == nil {
+			continue
+		}
+		result := make([]int, 0, i)
+		for group := 1; group <= i; group++ {
+			result = append(result, match[2*group+1]-match[2*group])
+		}
+		if match[1] < len(argStringsPattern) && argStringsPattern[match[1]] == 'O' {
+			for len(result) > 0 && result[len(result)-1] == 0 {
+				result = result[:len(result)-1]
+			}
+		}
+		return result
+	}
+
+	// return the list of arg string counts
+	return []int{}
+}
+
+// ParseOptional_ returns the possible interpretations of argString as an
+// option, or nil if it was meant to be a positional.
+func (ap *ArgumentParser) ParseOptional_(argString string) ([]OptionTuple, error) {
+	// if it's an empty string, it was meant to be a positional
+	if argString == "" {
+		return nil, nil
+	}
+
+	// if it doesn't start with a prefix, it was meant to be positional
+	if !strings.Contains(ap.PrefixChars, argString[:1]) {
+		return nil, nil
+	}
+
+	// if the option string is present in the parser, return the action
+	if action, found := ap.OptionStringActions[argString]; found {
+		return []OptionTuple{{Action: action, OptionString: argString}}, nil
+	}
+
+	// if it's just a single character, it was meant to be positional
+	if len(argString) == 1 {
+		return nil, nil
+	}
+
+	// if the option string before the "=" is present, return the action
+	if optionString, explicitArg, found := strings.Cut(argString, "="); found {
+		if action, found := ap.OptionStringActions[optionString]; found {
+			return []OptionTuple{{Action: action, OptionString: optionString, Sep: "=", ExplicitArg: &explicitArg}}, nil
+		}
+	}
+
+	// search through all possible prefixes of the option string
+	// and all actions in the parser for possible interpretations
+	optionTuples, err := ap.GetOptionTuples_(argString)
+	if err != nil {
+		return nil, err
+	}
+	if len(optionTuples) > 0 {
+		return optionTuples, nil
+	}
+
+	// if it was not found as an option, but it looks like a negative
+	// number, it was meant to be positional
+	// unless there are negative-number-like options
+	if ap.NegativeNumberMatcher.MatchString(argString) {
+		if len(ap.HasNegativeNumberOptionals) == 0 {
+			return nil, nil
+		}
+	}
+
+	// if it contains a space, it was meant to be a positional
+	if strings.Contains(argString, " ") {
+		return nil, nil
+	}
+
+	// it was meant to be an optional but there is no such option
+	// in this parser (though it might be a valid option in a subparser)
+	return []OptionTuple{{Action: nil, OptionString: argString}}, nil
+}
+
+// optionStrings returns the option strings of the parser in the order the
+// actions were added.
+func (ap *ArgumentParser) optionStrings() []string {
+	var optionStrings []string
+	for _, action := range ap.Actions {
+		for _, optionString := range action.Struct().OptionStrings {
+			if ap.OptionStringActions[optionString] == action {
+				optionStrings = append(optionStrings, optionString)
+			}
+		}
+	}
+	return optionStrings
+}
+
+// GetOptionTuples_ returns the actions whose option strings could be meant by
+// the abbreviated or concatenated optionString.
+func (ap *ArgumentParser) GetOptionTuples_(optionString string) ([]OptionTuple, error) {
+	var result []OptionTuple
+
+	// option strings starting with two prefix characters are only
+	// split at the '='
+	chars := ap.PrefixChars
+	if strings.Contains(chars, optionString[:1]) && strings.Contains(chars, optionString[1:2]) {
+		if ap.AllowAbbrev {
+			optionPrefix, explicitArg, found := strings.Cut(optionString, "=")
+			for _, candidate := range ap.optionStrings() {
+				if strings.HasPrefix(candidate, optionPrefix) {
+					tuple := OptionTuple{Action: ap.OptionStringActions[candidate], OptionString: candidate}
+					if found {
+						tuple.Sep = "="
+						tuple.ExplicitArg = &explicitArg
+					}
+					result = append(result, tuple)
+				}
+			}
+		}
+	} else if strings.Contains(chars, optionString[:1]) && !strings.Contains(chars, optionString[1:2]) {
+		// single character options can be concatenated with their arguments
+		// but multiple character options always have to have their argument
+		// separate
+		optionPrefix, explicitArg, found := strings.Cut(optionString, "=")
+		shortOptionPrefix := optionString[:2]
+		shortExplicitArg := optionString[2:]
+
+		for _, candidate := range ap.optionStrings() {
+			if candidate == shortOptionPrefix {
+				tuple := OptionTuple{Action: ap.OptionStringActions[candidate], OptionString: candidate, ExplicitArg: &shortExplicitArg}
+				result = append(result, tuple)
+			} else if ap.AllowAbbrev && strings.HasPrefix(candidate, optionPrefix) {
+				tuple := OptionTuple{Action: ap.OptionStringActions[candidate], OptionString: candidate}
+				if found {
+					tuple.Sep = "="
+					tuple.ExplicitArg = &explicitArg
+				}
+				result = append(result, tuple)
+			}
+		}
+	} else {
+		// shouldn't ever get here
+		return nil, NewArgumentError(nil, fmt.Sprintf("unexpected option string: %s", optionString))
+	}
+
+	// return the collected option tuples
+	return result, nil
+}
+
+// GetNargsPattern_ returns the regular expression matching the arg strings
+// pattern consumed by action.
+func (ap *ArgumentParser) GetNargsPattern_(action ActionInterface) string {
+	// in all examples below, we have to allow for '--' args
+	// which are represented as '-' in the pattern
+	var nargsPattern string
+	act := action.Struct()
+
+	switch nargs := act.Nargs.(type) {
+	case nil:
+		// the default (None) is assumed to be a single argument
+		nargsPattern = "(-*A-*)"
+	case int:
+		// all others should be integers
+		nargsPattern = fmt.Sprintf("(-*%s-*)", strings.Join(strings.Split(strings.Repeat("A", nargs), ""), "-*"))
+	case string:
+		switch nargs {
+		case OPTIONAL:
+			// allow zero or one arguments
+			nargsPattern = "(-*A?-*)"
+		case ZERO_OR_MORE:
+			// allow zero or more arguments
+			nargsPattern = "(-*[A-]*)"
+		case ONE_OR_MORE:
+			// allow one or more arguments
+			nargsPattern = "(-*A[A-]*)"
+		case REMAINDER:
+			// allow any number of options or arguments
+			if len(act.OptionStrings) > 0 {
+				nargsPattern = "([AO]*)"
+			} else {
+				nargsPattern = "(.*)"
+			}
+		case PARSER:
+			// allow one argument followed by any number of options or arguments
+			nargsPattern = "(-*A[-AO]*)"
+		case SUPPRESS:
+			// suppress action, like nargs=0
+			nargsPattern = "(-*-*)"
+		default:
+			panic("invalid nargs value")
+		}
+	default:
+		panic("invalid nargs value")
+	}
+
+	// if this is an optional action, -- is not allowed
+	if len(act.OptionStrings) > 0 {
+		nargsPattern = strings.ReplaceAll(nargsPattern, "-*", "")
+		nargsPattern = strings.ReplaceAll(nargsPattern, "-", "")
+	}
+
+	// return the pattern
+	return nargsPattern
 }
 
 // Alt command line argument parsing, allowing free intermix
 
-func (ap *ArgumentParser) ParseIntermixedArgs() {
+// ParseIntermixedArgs works like ParseArgs, but allows optionals and
+// positionals to be intermixed.
+func (ap *ArgumentParser) ParseIntermixedArgs(args []string, namespace *Namespace) (*Namespace, error) {
+	namespace, argv, err := ap.ParseKnownIntermixedArgs(args, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(argv) > 0 {
+		msg := fmt.Sprintf("unrecognized arguments: %s", strings.Join(argv, " "))
+		if ap.ExitOnError {
+			ap.Error(msg)
+		}
+		return nil, NewArgumentError(nil, msg)
+	}
+	ap.checkPrintConfig(namespace)
+	return namespace, nil
 }
 
-func (ap *ArgumentParser) ParseKnownIntermixedArgs() {
+// ParseKnownIntermixedArgs works like ParseKnownArgs, but allows optionals
+// and positionals to be intermixed.
+func (ap *ArgumentParser) ParseKnownIntermixedArgs(args []string, namespace *Namespace) (*Namespace, []string, error) {
+	// returns a namespace and list of extras
+	//
+	// positional can be freely intermixed with optionals.  optionals are
+	// first parsed with all positional arguments deactivated.  The 'extras'
+	// are then parsed.  If the parser definition is incompatible with the
+	// intermixed assumptions (e.g. use of REMAINDER, subparsers) a
+	// TypeError is raised.
+	for _, action := range ap.GetPositionalActions() {
+		if nargs := action.Struct().Nargs; nargs == PARSER || nargs == REMAINDER {
+			return nil, nil, fmt.Errorf("parse_intermixed_args: positional arg with nargs=%v", nargs)
+		}
+	}
+	return ap.ParseKnownArgs2(args, namespace, true)
 }
 
 // Value conversion methods
 
-func (ap *ArgumentParser) GetValues() {
+// GetValues converts the arg strings consumed by action to its value.
+func (ap *ArgumentParser) GetValues(action ActionInterface, argStrings []string) (any, error) {
+	act := action.Struct()
+	var value any
+	var err error
+
+	switch {
+	case act.Separator != "" && len(argStrings) > 0 && act.Nargs != REMAINDER && act.Nargs != PARSER:
+		// separated arguments produce a list of their items
+		values := []any{}
+		for _, argString := range argStrings {
+			items, err := splitSeparated(argString, act.Separator)
+			if err != nil {
+				return nil, NewArgumentError(act, err.Error())
+			}
+			for _, item := range items {
+				v, err := ap.GetValue(action, item)
+				if err != nil {
+					return nil, err
+				}
+				if err = ap.CheckValue(action, v); err != nil {
+					return nil, err
+				}
+				values = append(values, v)
+			}
+		}
+		value = values
+	case len(argStrings) == 0 && act.Nargs == OPTIONAL:
+		// optional argument produces a default when not present
+		if len(act.OptionStrings) > 0 {
+			value = act.Const
+		} else {
+			value = act.Default
+		}
+		if valueString, ok := value.(string); ok && valueString != SUPPRESS {
+			if value, err = ap.GetValue(action, valueString); err != nil {
+				return nil, err
+			}
+		}
+	case len(argStrings) == 0 && act.Nargs == ZERO_OR_MORE && len(act.OptionStrings) == 0:
+		// when nargs='*' on a positional, if there were no command-line
+		// args, use the default if it is anything other than None
+		if act.Default != nil {
+			value = act.Default
+		} else {
+			value = []any{}
+		}
+	case len(argStrings) == 1 && (act.Nargs == nil || act.Nargs == OPTIONAL):
+		// single argument or optional argument produces a single value
+		if value, err = ap.GetValue(action, argStrings[0]); err != nil {
+			return nil, err
+		}
+		if err = ap.CheckValue(action, value); err != nil {
+			return nil, err
+		}
+	case act.Nargs == REMAINDER:
+		// REMAINDER arguments convert all values, checking none
+		values := []any{}
+		for _, argString := range argStrings {
+			v, err := ap.GetValue(action, argString)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		value = values
+	case act.Nargs == PARSER:
+		// PARSER arguments convert all values, but check only the first
+		values := []any{}
+		for _, argString := range argStrings {
+			v, err := ap.GetValue(action, argString)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if err = ap.CheckValue(action, values[0]); err != nil {
+			return nil, err
+		}
+		value = values
+	case act.Nargs == SUPPRESS:
+		// SUPPRESS argument does not put anything in the namespace
+		value = SUPPRESS
+	default:
+		// all other types of nargs produce a list
+		values := []any{}
+		for _, argString := range argStrings {
+			v, err := ap.GetValue(action, argString)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		for _, v := range values {
+			if err = ap.CheckValue(action, v); err != nil {
+				return nil, err
+			}
+		}
+		value = values
+	}
+
+	// validate the values given as arguments, not the defaults
+	fromDefault := len(argStrings) == 0 && (act.Nargs == OPTIONAL || len(act.OptionStrings) == 0)
+	if !fromDefault && act.Nargs != REMAINDER && act.Nargs != PARSER && act.Nargs != SUPPRESS {
+		if err = validateValues(act, value); err != nil {
+			return nil, err
+		}
+	}
+
+	// return the converted value
+	return value, nil
+}
+
+// GetValue converts argString with the Type of action.
+func (ap *ArgumentParser) GetValue(action ActionInterface, argString string) (any, error) {
+	act := action.Struct()
+	typeFunc := act.Type
+	if _, ok := typeFunc.(string); ok || typeFunc == nil {
+		typeFunc = ap.RegistryGet("type", typeFunc, typeFunc)
+	}
+
+	// convert the value to the appropriate type
+	var result any
+	var err error
+	switch t := typeFunc.(type) {
+	case TypeFunc:
+		result, err = t(argString)
+	case *FileType:
+		result, err = t.open(argString, ap.stdin(), ap.stdout())
+	default:
+		return nil, NewArgumentError(act, fmt.Sprintf("%v is not callable", typeFunc))
+	}
+
+	if err != nil {
+		// ArgumentTypeErrors indicate errors
+		var typeErr *ArgumentTypeError
+		if errors.As(err, &typeErr) {
+			return nil, NewArgumentError(act, typeErr.Error())
+		}
+
+		// other errors indicate a conversion failure
+		msg := fmt.Sprintf("invalid %s value: '%s'", typeName(act.Type), argString)
+		return nil, NewArgumentError(act, msg)
+	}
+
+	// return the converted value
+	return result, nil
 }
 
-func (ap *ArgumentParser) GetValue() {
+// typeName returns the name of an Argument Type for error messages.
+func typeName(typeFunc Type) string {
+	switch t := typeFunc.(type) {
+	case string:
+		return t
+	case *FileType:
+		return t.Repr()
+	case nil:
+		return "str"
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(typeFunc).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
 }
 
-func (ap *ArgumentParser) CheckValue() {
+// CheckValue checks that value is one of the Choices of action.
+func (ap *ArgumentParser) CheckValue(action ActionInterface, value any) error {
+	// converted value must be one of the choices (if specified)
+	choices := action.Struct().Choices
+	if choices == nil {
+		return nil
+	}
+	for _, choice := range choices {
+		if reflect.DeepEqual(choice, value) {
+			return nil
+		}
+	}
+	choiceStrings := make([]string, len(choices))
+	for i, choice := range choices {
+		choiceStrings[i] = fmt.Sprintf("%v", choice)
+	}
+	msg := fmt.Sprintf("invalid choice: '%v' (choose from %s)", value, strings.Join(choiceStrings, ", "))
+	return NewArgumentError(action.Struct(), msg)
 }
 
 // Help-formatting methods
 
+// FormatUsage returns the usage message.
 func (ap *ArgumentParser) FormatUsage() string {
-	return "Usage: [options]: NOT IMPLEMENTED YET\n" // Example message, replace with actual implementation.
+	formatter := ap.GetFormatter_()
+	formatter.AddUsage(ap.Usage, ap.Actions, ap.MutuallyExclusiveGroups, "")
+	return formatter.FormatHelp()
 }
 
-// FormatHelp generates and returns the formatted help message.
-// This is a placeholder and should be implemented as per your application's needs.
+// FormatHelp returns the help message: usage, description, the argument
+// groups and the epilog.
 func (ap *ArgumentParser) FormatHelp() string {
-	return "Usage: [options]: NOT IMPLEMENTED YET\n" // Example message, replace with actual implementation.
+	formatter := ap.GetFormatter_()
+
+	// usage
+	formatter.AddUsage(ap.Usage, ap.Actions, ap.MutuallyExclusiveGroups, "")
+
+	// description
+	formatter.AddText(ap.Description)
+
+	// positionals, optionals and user-defined groups
+	for _, groupInterface := range ap.ActionGroups {
+		group := groupInterface.(*ArgumentGroup)
+		formatter.StartSection(group.Title)
+		formatter.AddText(group.Description)
+		formatter.AddArguments(group.GroupActions)
+		formatter.EndSection()
+	}
+
+	// epilog
+	formatter.AddText(ap.Epilog)
+
+	// determine help from format above
+	return formatter.FormatHelp()
 }
 
-func (ap *ArgumentParser) GetFormatter_() any {
-	return nil
+// GetFormatter_ returns a new formatter of FormatterClass, HelpFormatter by default.
+func (ap *ArgumentParser) GetFormatter_() HelpFormatterInterface {
+	// the zero ArgumentParser has no actions
+	if ap.ActionsContainer == nil {
+		ap.ActionsContainer = NewActionsContainer("", "-", nil, nil).(*ActionsContainer)
+	}
+
+	newFormatter, ok := ap.FormatterClass.(NewHelpFormatterFunc)
+	if !ok {
+		newFormatter = NewHelpFormatter
+	}
+	formatter := newFormatter(ap.Prog, 2, 24, 0)
+	formatter.Struct().EnvPrefix = ap.EnvPrefix
+	formatter.Struct().Constraints = ap.Constraints
+	return formatter
 }
 
 // Help-printing methods
 
-func (ap *ArgumentParser) PrintUsage() {
-
+// PrintUsage prints the usage message to file, or Stdout if file is nil.
+func (ap *ArgumentParser) PrintUsage(file io.Writer) {
+	if file == nil {
+		file = ap.stdout()
+	}
+	ap.printMessage(ap.FormatUsage(), file)
 }
 
-// PrintHelp prints the help message to the provided file or stdout if no file is specified.
-func (ap *ArgumentParser) PrintHelp(file *os.File) {
+// PrintHelp prints the help message to file, or Stdout if file is nil.
+func (ap *ArgumentParser) PrintHelp(file io.Writer) {
 	if file == nil {
-		file = os.Stdout
+		file = ap.stdout()
 	}
 	ap.printMessage(ap.FormatHelp(), file)
 }
@@ -135,13 +1651,13 @@ func (ap *ArgumentParser) PrintHelp(file *os.File) {
 // 	return nil
 // }
 
-// printMessage prints the given message to the specified file or stderr if no file is provided.
-func (ap *ArgumentParser) printMessage(message string, file *os.File) {
+// printMessage writes message to file, or Stderr if file is nil.
+func (ap *ArgumentParser) printMessage(message string, file io.Writer) {
 	if message != "" {
 		if file == nil {
-			file = os.Stderr
+			file = ap.stderr()
 		}
-		file.WriteString(message)
+		io.WriteString(file, message)
 		// _, err := file.WriteString(message)
 		// if err != nil {
 		// 	// Handle potential write errors silently, as in the Python example.
@@ -149,22 +1665,56 @@ func (ap *ArgumentParser) printMessage(message string, file *os.File) {
 	}
 }
 
+// stdout returns Stdout, os.Stdout for the zero ArgumentParser.
+func (ap *ArgumentParser) stdout() io.Writer {
+	if ap.Stdout == nil {
+		return os.Stdout
+	}
+	return ap.Stdout
+}
+
+// stderr returns Stderr, os.Stderr for the zero ArgumentParser.
+func (ap *ArgumentParser) stderr() io.Writer {
+	if ap.Stderr == nil {
+		return os.Stderr
+	}
+	return ap.Stderr
+}
+
+// stdin returns Stdin, os.Stdin for the zero ArgumentParser.
+func (ap *ArgumentParser) stdin() io.Reader {
+	if ap.Stdin == nil {
+		return os.Stdin
+	}
+	return ap.Stdin
+}
+
 // Exiting methods
 
-// Exit prints a message to stderr (if provided) and exits with the given status.
+// Exit prints a message to Stderr (if provided) and exits with the given status.
 func (ap *ArgumentParser) Exit(status int, message string) {
 
 	if message != "" {
-		ap.printMessage(message, os.Stderr)
+		ap.printMessage(message, ap.stderr())
 	}
 
+	// a REPL running the parser or one of its parents keeps running
+	for parser := ap; parser != nil; parser = parser.parent {
+		if parser.exitHook != nil {
+			parser.exitHook(status)
+			return
+		}
+	}
 	os.Exit(status)
 }
 
+// Error prints the usage and message to Stderr and exits with status 2.
 func (ap *ArgumentParser) Error(message string) {
-
+	ap.PrintUsage(ap.stderr())
+	ap.Exit(2, fmt.Sprintf("%s: error: %s\n", ap.Prog, message))
 }
 
+// Warning prints a warning message to Stderr.
 func (ap *ArgumentParser) Warning(message string) {
-
+	ap.printMessage(fmt.Sprintf("%s: warning: %s\n", ap.Prog, message), ap.stderr())
 }
@@ -3,51 +3,80 @@ package argparse
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 // BooleanOptionalAction represents a boolean flag action with support for both `--flag` and `--no-flag`.
 type BooleanOptionalAction struct {
-	Action // Embed Action to inherit its behavior
+	*Action
+
+	NegationPrefix string // The prefix of the negative options after the prefix characters, "no-" by default, e.g. "disable-"
+	negatives      map[string]bool
 }
 
-// NewBooleanOptionalAction creates a new BooleanOptionalAction object.
+// NewBooleanOptionalAction creates a new BooleanOptionalAction object. A
+// negative option is added for each long option, with the prefix characters
+// of the option, e.g. --no-color for --color or ++no-color for ++color.
 func NewBooleanOptionalAction(argument *Argument) ActionInterface {
+	negationPrefix := argument.NegationPrefix
+	if negationPrefix == "" {
+		negationPrefix = "no-"
+	}
+
 	// Validate and process option strings
 	var _optionStrings []string
-
+	negatives := make(map[string]bool)
 	for _, optionString := range argument.OptionStrings {
 		_optionStrings = append(_optionStrings, optionString)
 
-		if strings.HasPrefix(optionString, "--") {
-			if strings.HasPrefix(optionString, "--no-") {
+		// long options start with two prefix characters
+		if len(optionString) > 2 && optionString[0] == optionString[1] && !unicode.IsLetter(rune(optionString[0])) {
+			prefix, name := optionString[:2], optionString[2:]
+			if strings.HasPrefix(name, negationPrefix) {
 				panic(fmt.Errorf("invalid option name %q for BooleanOptionalAction", optionString))
 			}
-			optionString = "--no-" + optionString[2:]
+			optionString = prefix + negationPrefix + name
 			_optionStrings = append(_optionStrings, optionString)
+			negatives[optionString] = true
 		}
 	}
 
-	action := Action{
+	// the default is shown in the help
+	help := argument.Help
+	if help != "" && help != SUPPRESS && argument.Default != nil && argument.Default != SUPPRESS && !strings.Contains(help, "%(default)") {
+		help += " (default: %(default)s)"
+	}
+
+	action := &Action{
 		OptionStrings: _optionStrings,
 		Dest:          argument.Dest,
 		Nargs:         0,
 		Default:       argument.Default,
 		Required:      argument.Required,
-		Help:          argument.Help,
+		Help:          help,
 		Deprecated:    argument.Deprecated,
+		Env:           argument.Env,
+		Prompt:        argument.Prompt,
+		PromptHidden:  argument.PromptHidden,
+		Completer:     argument.Completer,
 	}
 
-	return &BooleanOptionalAction{Action: action}
+	return &BooleanOptionalAction{Action: action, NegationPrefix: negationPrefix, negatives: negatives}
 }
 
-// Call executes the action when the option is encountered on the command line.
+// Call executes the action when the option is encountered on the command
+// line. The values from the other sources are a single boolean string, e.g.
+// "yes" or "0".
 func (a *BooleanOptionalAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
-	for _, _optionString := range a.OptionStrings {
-		if _optionString == optionString {
-			namespace.Set(a.Dest, !strings.HasPrefix(_optionString, "--no-"))
-			break
+	if items, ok := values.([]any); ok && len(items) == 1 {
+		value, err := parseBool(fmt.Sprintf("%v", items[0]))
+		if err != nil {
+			return NewArgumentError(a.Action, err.Error())
 		}
+		namespace.Set(a.Dest, value)
+		return nil
 	}
+	namespace.Set(a.Dest, !a.negatives[optionString])
 	return nil
 }
 
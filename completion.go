@@ -0,0 +1,614 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Shells supported by FormatCompletion.
+const (
+	BASH       = "bash"
+	ZSH        = "zsh"
+	FISH       = "fish"
+	POWERSHELL = "powershell"
+)
+
+// completionArgument is the shell independent description of an action
+// used to generate completion scripts.
+type completionArgument struct {
+	OptionStrings []string // The option strings, empty for positionals
+	Dest          string   // The destination name, used as a message for positionals
+	Help          string   // The help description, collapsed to a single line
+	TakesValue    bool     // Whether the option consumes command-line arguments
+	Repeatable    bool     // Whether the option or positional may be given more than once
+	Choices       []string // The valid values for this argument
+	Files         bool     // Whether the values are file names (FileType)
+}
+
+// completionCommand is the shell independent description of a parser
+// and its nested subparsers used to generate completion scripts.
+type completionCommand struct {
+	Name        string   // The subcommand name, the program name for the root parser
+	Aliases     []string // The subcommand aliases
+	Path        []string // The names from the root parser down to this command
+	Help        string   // The subcommand help
+	PrefixChars string   // The prefix characters of the parser
+	Options     []*completionArgument
+	Positionals []*completionArgument
+	Subcommands []*completionCommand
+}
+
+// Names returns the subcommand name followed by its aliases.
+func (c *completionCommand) Names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// Key returns the command path joined by spaces, identifying the command in scripts.
+func (c *completionCommand) Key() string {
+	return strings.Join(c.Path, " ")
+}
+
+// Walk calls fn for the command and all nested subcommands, depth first.
+func (c *completionCommand) Walk(fn func(*completionCommand)) {
+	fn(c)
+	for _, sub := range c.Subcommands {
+		sub.Walk(fn)
+	}
+}
+
+// FormatCompletion generates a static completion script for the given shell,
+// one of BASH, ZSH, FISH or POWERSHELL.
+//
+// Options with help SUPPRESS, deprecated options and deprecated subcommands
+// are not offered, arguments with a FileType complete file names.
+func (ap *ArgumentParser) FormatCompletion(shell string) (string, error) {
+	root := newCompletionCommand(ap, ap.Prog, nil, "")
+	switch shell {
+	case BASH:
+		return formatBashCompletion(root), nil
+	case ZSH:
+		return formatZshCompletion(root), nil
+	case FISH:
+		return formatFishCompletion(root), nil
+	case POWERSHELL:
+		return formatPowerShellCompletion(root), nil
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+}
+
+// PrintCompletion prints the completion script for the given shell to file, or Stdout if file is nil.
+func (ap *ArgumentParser) PrintCompletion(shell string, file io.Writer) error {
+	script, err := ap.FormatCompletion(shell)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		file = ap.stdout()
+	}
+	ap.printMessage(script, file)
+	return nil
+}
+
+// newCompletionCommand collects the completion data of parser and its subparsers.
+func newCompletionCommand(parser *ArgumentParser, name string, parentPath []string, help string) *completionCommand {
+	command := &completionCommand{
+		Name:        name,
+		Path:        append(append([]string{}, parentPath...), name),
+		Help:        singleLine(help),
+		PrefixChars: parser.PrefixChars,
+	}
+
+	for _, actionInterface := range parser.Actions {
+		action := actionInterface.Struct()
+		if action.Help == SUPPRESS {
+			continue
+		}
+
+		// subparsers are collected as nested commands, in the order they were added
+		if subparsers, ok := actionInterface.(*SubParsersAction); ok {
+			command.Subcommands = append(command.Subcommands, newCompletionSubcommands(subparsers, command.Path)...)
+			continue
+		}
+
+		if action.Deprecated {
+			continue
+		}
+
+		argument := &completionArgument{
+			OptionStrings: action.OptionStrings,
+			Dest:          action.Dest,
+			Help:          singleLine(action.Help),
+			TakesValue:    action.Nargs != 0,
+		}
+		for _, choice := range action.Choices {
+			argument.Choices = append(argument.Choices, fmt.Sprintf("%v", choice))
+		}
+		if _, ok := action.Type.(*FileType); ok {
+			argument.Files = true
+		}
+		if _, ok := actionInterface.(*ConfigAction); ok {
+			argument.Files = true
+		}
+
+		if len(action.OptionStrings) > 0 {
+			switch actionInterface.(type) {
+			case *CountAction, *AppendAction, *AppendConstAction, *ExtendAction, *MapAction, *ExtendMapAction:
+				argument.Repeatable = true
+			}
+			command.Options = append(command.Options, argument)
+		} else {
+			switch action.Nargs {
+			case ZERO_OR_MORE, ONE_OR_MORE, REMAINDER:
+				argument.Repeatable = true
+			}
+			command.Positionals = append(command.Positionals, argument)
+		}
+	}
+
+	return command
+}
+
+// newCompletionSubcommands collects the subcommands of a SubParsersAction,
+// aliases are attached to the command of the parser they refer to.
+func newCompletionSubcommands(subparsers *SubParsersAction, parentPath []string) []*completionCommand {
+	helps := make(map[string]string)
+	for _, choiceAction := range subparsers.ChoicesActions {
+		helps[choiceAction.Struct().Dest] = choiceAction.Struct().Help
+	}
+
+	commands := []*completionCommand{}
+	byParser := make(map[*ArgumentParser]*completionCommand)
+	for _, choice := range subparsers.Choices {
+		name := fmt.Sprintf("%v", choice)
+		if _, deprecated := subparsers.Deprecated[name]; deprecated {
+			continue
+		}
+		parser := subparsers.NameParserMap[name]
+		if parser == nil {
+			// plugins have no known arguments
+			commands = append(commands, &completionCommand{Name: name, Path: append(append([]string{}, parentPath...), name), Help: singleLine(helps[name])})
+			continue
+		}
+		if command, found := byParser[parser]; found {
+			command.Aliases = append(command.Aliases, name)
+			continue
+		}
+		command := newCompletionCommand(parser, name, parentPath, helps[name])
+		byParser[parser] = command
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+var whitespaceMatcher = regexp.MustCompile(`\s+`)
+var identifierMatcher = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// singleLine collapses all whitespace in text, so it fits a single script line.
+func singleLine(text string) string {
+	if text == SUPPRESS {
+		return ""
+	}
+	return strings.TrimSpace(whitespaceMatcher.ReplaceAllString(text, " "))
+}
+
+// completionIdentifier converts the parts to a shell function name.
+func completionIdentifier(parts ...string) string {
+	return identifierMatcher.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
+
+// shellQuote quotes s for POSIX shells and zsh.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, which escapes quotes with a backslash inside single quotes.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// powerShellQuote quotes s as a PowerShell verbatim string.
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteAll quotes every item of values with quote.
+func quoteAll(values []string, quote func(string) string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+	return quoted
+}
+
+// bracketExpression returns a glob bracket expression matching any of the prefix chars.
+func bracketExpression(prefixChars string) string {
+	if strings.Contains(prefixChars, "-") {
+		prefixChars = "-" + strings.ReplaceAll(prefixChars, "-", "")
+	}
+	return "[" + prefixChars + "]"
+}
+
+func formatBashCompletion(root *completionCommand) string {
+	var b strings.Builder
+	function := "_" + completionIdentifier(root.Name) + "_completion"
+
+	fmt.Fprintf(&b, "# bash completion for %s, generated by argparse\n\n", root.Name)
+	fmt.Fprintf(&b, "%s() {\n", function)
+	b.WriteString("    local cur prev cmd i\n")
+	b.WriteString("    COMPREPLY=()\n")
+	b.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(&b, "    cmd=%s\n\n", shellQuote(root.Key()))
+
+	// find the innermost subcommand already typed
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        case \"${cmd} ${COMP_WORDS[i]}\" in\n")
+	root.Walk(func(command *completionCommand) {
+		for _, sub := range command.Subcommands {
+			patterns := []string{}
+			for _, name := range sub.Names() {
+				patterns = append(patterns, shellQuote(command.Key()+" "+name))
+			}
+			fmt.Fprintf(&b, "            %s) cmd=%s ;;\n", strings.Join(patterns, "|"), shellQuote(sub.Key()))
+		}
+	})
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+
+	b.WriteString("    case \"${cmd}\" in\n")
+	root.Walk(func(command *completionCommand) {
+		fmt.Fprintf(&b, "        %s)\n", shellQuote(command.Key()))
+
+		// complete the value of the previous option
+		b.WriteString("            case \"${prev}\" in\n")
+		optionStrings := []string{}
+		for _, option := range command.Options {
+			optionStrings = append(optionStrings, option.OptionStrings...)
+			if !option.TakesValue {
+				continue
+			}
+			fmt.Fprintf(&b, "                %s)\n", strings.Join(quoteAll(option.OptionStrings, shellQuote), "|"))
+			if len(option.Choices) > 0 {
+				fmt.Fprintf(&b, "                    COMPREPLY=($(compgen -W %s -- \"${cur}\"))\n", shellQuote(strings.Join(option.Choices, " ")))
+			} else if option.Files {
+				b.WriteString("                    COMPREPLY=($(compgen -f -- \"${cur}\"))\n")
+			}
+			b.WriteString("                    return\n")
+			b.WriteString("                    ;;\n")
+		}
+		b.WriteString("            esac\n")
+
+		// complete option strings, or subcommands and positional values
+		words := []string{}
+		files := false
+		for _, sub := range command.Subcommands {
+			words = append(words, sub.Names()...)
+		}
+		for _, positional := range command.Positionals {
+			words = append(words, positional.Choices...)
+			files = files || positional.Files
+		}
+		fmt.Fprintf(&b, "            if [[ \"${cur}\" == %s* ]]; then\n", bracketExpression(command.PrefixChars))
+		fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W %s -- \"${cur}\"))\n", shellQuote(strings.Join(optionStrings, " ")))
+		b.WriteString("            else\n")
+		fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W %s -- \"${cur}\"))\n", shellQuote(strings.Join(words, " ")))
+		if files {
+			b.WriteString("                COMPREPLY+=($(compgen -f -- \"${cur}\"))\n")
+		}
+		b.WriteString("            fi\n")
+		b.WriteString("            ;;\n")
+	})
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "complete -F %s %s\n", function, shellQuote(root.Name))
+	return b.String()
+}
+
+// zshEscape escapes text used inside an _arguments description or message.
+func zshEscape(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`)
+	return replacer.Replace(text)
+}
+
+// zshAction returns the _arguments action completing the values of argument.
+func zshAction(argument *completionArgument) string {
+	if len(argument.Choices) > 0 {
+		return "(" + strings.Join(quoteAll(argument.Choices, zshEscape), " ") + ")"
+	}
+	if argument.Files {
+		return "_files"
+	}
+	return " "
+}
+
+func formatZshCompletion(root *completionCommand) string {
+	var b strings.Builder
+	function := "_" + completionIdentifier(root.Name)
+
+	fmt.Fprintf(&b, "#compdef %s\n\n", root.Name)
+	fmt.Fprintf(&b, "# zsh completion for %s, generated by argparse\n\n", root.Name)
+
+	root.Walk(func(command *completionCommand) {
+		fmt.Fprintf(&b, "_%s() {\n", completionIdentifier(command.Path...))
+		b.WriteString("    local curcontext=\"$curcontext\" state line\n")
+		b.WriteString("    typeset -A opt_args\n\n")
+		b.WriteString("    _arguments -C \\\n")
+
+		for _, option := range command.Options {
+			spec := ""
+			if option.Repeatable {
+				spec = "*"
+			}
+			if len(option.OptionStrings) > 1 {
+				// mutually exclude the option strings, unless the option can be repeated
+				exclusion := ""
+				if !option.Repeatable {
+					exclusion = fmt.Sprintf("(%s)", strings.Join(option.OptionStrings, " "))
+				}
+				spec = fmt.Sprintf("%s{%s}", shellQuote(exclusion+spec), strings.Join(option.OptionStrings, ","))
+			} else {
+				spec = shellQuote(spec + option.OptionStrings[0])
+			}
+			description := ""
+			if option.Help != "" {
+				description = fmt.Sprintf("[%s]", zshEscape(option.Help))
+			}
+			if option.TakesValue {
+				description += fmt.Sprintf(":%s:%s", zshEscape(option.Dest), zshAction(option))
+			}
+			fmt.Fprintf(&b, "        %s%s \\\n", spec, shellQuote(description))
+		}
+
+		for _, positional := range command.Positionals {
+			message := positional.Help
+			if message == "" {
+				message = positional.Dest
+			}
+			prefix := ""
+			if positional.Repeatable {
+				prefix = "*"
+			}
+			spec := fmt.Sprintf("%s:%s:%s", prefix, zshEscape(message), zshAction(positional))
+			fmt.Fprintf(&b, "        %s \\\n", shellQuote(spec))
+		}
+
+		if len(command.Subcommands) > 0 {
+			b.WriteString("        ': :->command' \\\n")
+			b.WriteString("        '*:: :->args' \\\n")
+		}
+		b.WriteString("        && return 0\n")
+
+		if len(command.Subcommands) > 0 {
+			b.WriteString("\n    case $state in\n")
+			b.WriteString("        command)\n")
+			b.WriteString("            local -a commands\n")
+			b.WriteString("            commands=(\n")
+			for _, sub := range command.Subcommands {
+				for _, name := range sub.Names() {
+					fmt.Fprintf(&b, "                %s\n", shellQuote(strings.ReplaceAll(name, ":", `\:`)+":"+sub.Help))
+				}
+			}
+			b.WriteString("            )\n")
+			b.WriteString("            _describe -t commands 'command' commands\n")
+			b.WriteString("            ;;\n")
+			b.WriteString("        args)\n")
+			b.WriteString("            case $line[1] in\n")
+			for _, sub := range command.Subcommands {
+				fmt.Fprintf(&b, "                %s) _%s ;;\n", strings.Join(quoteAll(sub.Names(), shellQuote), "|"), completionIdentifier(sub.Path...))
+			}
+			b.WriteString("            esac\n")
+			b.WriteString("            ;;\n")
+			b.WriteString("    esac\n")
+		}
+		b.WriteString("}\n\n")
+	})
+
+	fmt.Fprintf(&b, "if [ \"$funcstack[1]\" = \"%s\" ]; then\n", function)
+	fmt.Fprintf(&b, "    %s \"$@\"\n", function)
+	b.WriteString("else\n")
+	fmt.Fprintf(&b, "    compdef %s %s\n", function, shellQuote(root.Name))
+	b.WriteString("fi\n")
+	return b.String()
+}
+
+// fishOption returns the complete flags describing the option string.
+func fishOption(optionString string) string {
+	switch {
+	case strings.HasPrefix(optionString, "--"):
+		return "-l " + fishQuote(optionString[2:])
+	case strings.HasPrefix(optionString, "-") && len(optionString) == 2:
+		return "-s " + fishQuote(optionString[1:])
+	case strings.HasPrefix(optionString, "-"):
+		return "-o " + fishQuote(optionString[1:])
+	default:
+		// fish only knows dash prefixed options, offer others as arguments
+		return "-a " + fishQuote(optionString)
+	}
+}
+
+func formatFishCompletion(root *completionCommand) string {
+	var b strings.Builder
+	function := "__fish_" + completionIdentifier(root.Name) + "_using_command"
+	name := fishQuote(root.Name)
+
+	fmt.Fprintf(&b, "# fish completion for %s, generated by argparse\n\n", root.Name)
+
+	// the helper tests the innermost subcommand already typed
+	fmt.Fprintf(&b, "function %s\n", function)
+	fmt.Fprintf(&b, "    set -l command %s\n", fishQuote(root.Key()))
+	b.WriteString("    for token in (commandline -opc)[2..-1]\n")
+	b.WriteString("        switch \"$command $token\"\n")
+	root.Walk(func(command *completionCommand) {
+		for _, sub := range command.Subcommands {
+			patterns := []string{}
+			for _, subName := range sub.Names() {
+				patterns = append(patterns, fishQuote(command.Key()+" "+subName))
+			}
+			fmt.Fprintf(&b, "            case %s\n", strings.Join(patterns, " "))
+			fmt.Fprintf(&b, "                set command %s\n", fishQuote(sub.Key()))
+		}
+	})
+	b.WriteString("        end\n")
+	b.WriteString("    end\n")
+	b.WriteString("    test \"$command\" = \"$argv\"\n")
+	b.WriteString("end\n")
+
+	root.Walk(func(command *completionCommand) {
+		condition := fmt.Sprintf("-n \"%s %s\"", function, fishQuote(command.Key()))
+		b.WriteString("\n")
+
+		// positional arguments that are not file names disable file completion
+		files := false
+		for _, positional := range command.Positionals {
+			files = files || positional.Files || len(positional.Choices) == 0
+		}
+		if !files {
+			fmt.Fprintf(&b, "complete -c %s %s -f\n", name, condition)
+		}
+
+		for _, option := range command.Options {
+			flags := []string{}
+			for _, optionString := range option.OptionStrings {
+				flags = append(flags, fishOption(optionString))
+			}
+			if option.TakesValue {
+				flags = append(flags, "-r")
+				if len(option.Choices) > 0 {
+					flags = append(flags, "-f", "-a", fishQuote(strings.Join(option.Choices, " ")))
+				} else if option.Files {
+					flags = append(flags, "-F")
+				}
+			}
+			if option.Help != "" {
+				flags = append(flags, "-d", fishQuote(option.Help))
+			}
+			fmt.Fprintf(&b, "complete -c %s %s %s\n", name, condition, strings.Join(flags, " "))
+		}
+
+		for _, sub := range command.Subcommands {
+			for _, subName := range sub.Names() {
+				line := fmt.Sprintf("complete -c %s %s -f -a %s", name, condition, fishQuote(subName))
+				if sub.Help != "" {
+					line += " -d " + fishQuote(sub.Help)
+				}
+				b.WriteString(line + "\n")
+			}
+		}
+
+		for _, positional := range command.Positionals {
+			if len(positional.Choices) > 0 {
+				line := fmt.Sprintf("complete -c %s %s -f -a %s", name, condition, fishQuote(strings.Join(positional.Choices, " ")))
+				if positional.Help != "" {
+					line += " -d " + fishQuote(positional.Help)
+				}
+				b.WriteString(line + "\n")
+			} else if positional.Files {
+				fmt.Fprintf(&b, "complete -c %s %s -F\n", name, condition)
+			}
+		}
+	})
+	return b.String()
+}
+
+// powerShellResult returns a CompletionResult constructor call, the tooltip must not be empty.
+func powerShellResult(text string, resultType string, tooltip string) string {
+	if tooltip == "" {
+		tooltip = text
+	}
+	return fmt.Sprintf(
+		"[System.Management.Automation.CompletionResult]::new(%s, %s, '%s', %s)",
+		powerShellQuote(text), powerShellQuote(text), resultType, powerShellQuote(tooltip),
+	)
+}
+
+func formatPowerShellCompletion(root *completionCommand) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# powershell completion for %s, generated by argparse\n\n", root.Name)
+	fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", powerShellQuote(root.Name))
+	b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+
+	// find the innermost subcommand and the previous word before the cursor
+	fmt.Fprintf(&b, "    $command = %s\n", powerShellQuote(root.Key()))
+	b.WriteString("    $previous = ''\n")
+	b.WriteString("    foreach ($element in $commandAst.CommandElements | Select-Object -Skip 1) {\n")
+	b.WriteString("        if ($element.Extent.EndOffset -ge $cursorPosition) {\n")
+	b.WriteString("            break\n")
+	b.WriteString("        }\n")
+	b.WriteString("        $word = $element.ToString()\n")
+	b.WriteString("        switch -casesensitive (\"$command $word\") {\n")
+	root.Walk(func(command *completionCommand) {
+		for _, sub := range command.Subcommands {
+			for _, name := range sub.Names() {
+				fmt.Fprintf(&b, "            %s { $command = %s }\n", powerShellQuote(command.Key()+" "+name), powerShellQuote(sub.Key()))
+			}
+		}
+	})
+	b.WriteString("        }\n")
+	b.WriteString("        $previous = $word\n")
+	b.WriteString("    }\n\n")
+
+	b.WriteString("    $values = $null\n")
+	b.WriteString("    $candidates = @()\n")
+	b.WriteString("    switch -casesensitive ($command) {\n")
+	root.Walk(func(command *completionCommand) {
+		fmt.Fprintf(&b, "        %s {\n", powerShellQuote(command.Key()))
+
+		// options taking a value; free values and files fall back to path completion
+		cases := []string{}
+		for _, option := range command.Options {
+			if !option.TakesValue {
+				continue
+			}
+			values := "@()"
+			if len(option.Choices) > 0 {
+				values = "@(" + strings.Join(quoteAll(option.Choices, powerShellQuote), ", ") + ")"
+			}
+			for _, optionString := range option.OptionStrings {
+				cases = append(cases, fmt.Sprintf("                %s { $values = %s }\n", powerShellQuote(optionString), values))
+			}
+		}
+		if len(cases) > 0 {
+			b.WriteString("            switch -casesensitive ($previous) {\n")
+			b.WriteString(strings.Join(cases, ""))
+			b.WriteString("            }\n")
+		}
+
+		b.WriteString("            $candidates = @(\n")
+		for _, option := range command.Options {
+			for _, optionString := range option.OptionStrings {
+				fmt.Fprintf(&b, "                %s\n", powerShellResult(optionString, "ParameterName", option.Help))
+			}
+		}
+		for _, sub := range command.Subcommands {
+			for _, name := range sub.Names() {
+				fmt.Fprintf(&b, "                %s\n", powerShellResult(name, "ParameterValue", sub.Help))
+			}
+		}
+		for _, positional := range command.Positionals {
+			for _, choice := range positional.Choices {
+				fmt.Fprintf(&b, "                %s\n", powerShellResult(choice, "ParameterValue", positional.Help))
+			}
+		}
+		b.WriteString("            )\n")
+		b.WriteString("        }\n")
+	})
+	b.WriteString("    }\n\n")
+
+	b.WriteString("    if ($null -ne $values) {\n")
+	b.WriteString("        $candidates = @($values | ForEach-Object {\n")
+	b.WriteString("            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+	b.WriteString("        })\n")
+	b.WriteString("    }\n")
+	b.WriteString("    $candidates | Where-Object { $_.CompletionText -like \"$wordToComplete*\" }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,219 @@
+package argparse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConfigEntry is a value read from a configuration file.
+type ConfigEntry struct {
+	Keys  []string // The section names followed by the key, e.g. ["build", "output"]
+	Value any      // A string, bool, int64, float64, nil or a []any of them
+	File  string   // The file the value was read from
+	Line  int      // The line of the key in the file
+}
+
+// ConfigParserFunc parses the content of a configuration file into entries,
+// the parsers are registered in the "config" registry by file extension.
+type ConfigParserFunc = func(data []byte) ([]ConfigEntry, error)
+
+// ConfigError represents an error in a configuration file.
+type ConfigError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// NewConfigError creates a new ConfigError, line is 0 if unknown.
+func NewConfigError(file string, line int, message string) *ConfigError {
+	return &ConfigError{
+		File:    file,
+		Line:    line,
+		Message: message,
+	}
+}
+
+// Error implements the error interface for ConfigError.
+func (e *ConfigError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// ReadConfigFile_ reads the entries of a configuration file, using the
+// parser registered for its extension.
+func (ap *ArgumentParser) ReadConfigFile_(path string) ([]ConfigEntry, error) {
+	parse, ok := ap.RegistryGet("config", strings.ToLower(filepath.Ext(path)), nil).(ConfigParserFunc)
+	if !ok {
+		return nil, NewConfigError(path, 0, "unsupported configuration file format")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewConfigError(path, 0, fmt.Sprintf("can't open: %v", err))
+	}
+
+	entries, err := parse(data)
+	if err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			configErr.File = path
+			return nil, configErr
+		}
+		return nil, NewConfigError(path, 0, err.Error())
+	}
+	for i := range entries {
+		entries[i].File = path
+	}
+	return entries, nil
+}
+
+// ConfigPaths_ returns the configuration files named by the config actions
+// in argStrings, or the existing ConfigFiles if there are none.
+func (ap *ArgumentParser) ConfigPaths_(argStrings []string) []string {
+	var paths []string
+	for i := 0; i < len(argStrings) && argStrings[i] != "--"; i++ {
+		optionTuples, err := ap.ParseOptional_(argStrings[i])
+		if err != nil || len(optionTuples) != 1 {
+			continue
+		}
+		if _, ok := optionTuples[0].Action.(*ConfigAction); !ok {
+			continue
+		}
+		if explicitArg := optionTuples[0].ExplicitArg; explicitArg != nil {
+			paths = append(paths, *explicitArg)
+		} else if i+1 < len(argStrings) {
+			paths = append(paths, argStrings[i+1])
+			i++
+		}
+	}
+	if len(paths) > 0 {
+		return paths
+	}
+
+	// search the default configuration files, ~ is the home directory
+	for _, path := range ap.ConfigFiles {
+		if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+			path = filepath.Join(home, path[2:])
+		}
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// LoadConfig_ reads the configuration files and returns the entries of the
+// parser's actions and defaults. The sections named after subcommands are
+// handed to the subparsers, other keys are reported as errors.
+func (ap *ArgumentParser) LoadConfig_(argStrings []string) ([]ConfigEntry, error) {
+	entries := append([]ConfigEntry{}, ap.inheritedConfig...)
+	for _, path := range ap.ConfigPaths_(argStrings) {
+		fileEntries, err := ap.ReadConfigFile_(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	var own []ConfigEntry
+	sections := make(map[*ArgumentParser][]ConfigEntry)
+	mapEntries := make(map[*MapAction]int)
+	for _, entry := range entries {
+		key := entry.Keys[0]
+		if len(entry.Keys) == 1 {
+			if _, found := ap.Defaults[key]; found || ap.ConfigAction_(key) != nil {
+				own = append(own, entry)
+				continue
+			}
+		} else if action := mapActionOf(ap.ConfigAction_(key)); action != nil {
+			// the keys of the section of a map action are its keys, the
+			// entries of a file are taken together
+			item := strings.Join(entry.Keys[1:], ".") + action.KeySeparator + configString(entry.Value)
+			if index, found := mapEntries[action]; found && own[index].File == entry.File {
+				own[index].Value = append(own[index].Value.([]any), item)
+				continue
+			}
+			mapEntries[action] = len(own)
+			own = append(own, ConfigEntry{Keys: []string{key}, Value: []any{item}, File: entry.File, Line: entry.Line})
+			continue
+		} else if ap.Subparsers != nil {
+			if subparser, found := ap.Subparsers.NameParserMap[key]; found {
+				entry.Keys = entry.Keys[1:]
+				sections[subparser] = append(sections[subparser], entry)
+				continue
+			}
+		}
+		return nil, NewConfigError(entry.File, entry.Line, fmt.Sprintf("unknown key '%s'", strings.Join(entry.Keys, ".")))
+	}
+
+	if ap.Subparsers != nil {
+		for _, subparser := range ap.Subparsers.NameParserMap {
+			subparser.inheritedConfig = sections[subparser]
+		}
+	}
+	return own, nil
+}
+
+// ConfigAction_ returns the action whose dest is key, dashes in key match
+// underscores, or nil if there is none.
+func (ap *ArgumentParser) ConfigAction_(key string) ActionInterface {
+	for _, action := range ap.Actions {
+		switch action.(type) {
+		case *HelpAction, *VersionAction, *SubParsersAction, *ConfigAction:
+			continue
+		}
+		dest := action.Struct().Dest
+		if dest != SUPPRESS && (dest == key || dest == strings.ReplaceAll(key, "-", "_")) {
+			return action
+		}
+	}
+	return nil
+}
+
+// ConfigArgStrings_ converts the value of a configuration entry to the arg
+// strings of each time the action is taken. Arrays provide several values,
+// strings of actions accepting several values are split at LIST_SEPARATOR,
+// or at their Separator by GetValues.
+func (ap *ArgumentParser) ConfigArgStrings_(action ActionInterface, value any) ([][]string, error) {
+	var items []string
+	if separator := action.Struct().Separator; separator != "" {
+		// the items of separated values are split by GetValues
+		if values, ok := value.([]any); ok {
+			for _, item := range values {
+				items = append(items, configString(item))
+			}
+			items = []string{joinSeparated(items, separator)}
+		} else {
+			items = []string{configString(value)}
+		}
+	} else if values, ok := value.([]any); ok {
+		for _, item := range values {
+			items = append(items, configString(item))
+		}
+	} else if acceptsList(action) {
+		items = splitList(configString(value))
+	} else {
+		items = []string{configString(value)}
+	}
+	return ap.SourceArgStrings_(action, items)
+}
+
+// configString formats a configuration value as an arg string.
+func configString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,45 @@
+package argparse
+
+import (
+	"fmt"
+)
+
+// ConfigAction names a configuration file to load, e.g. --config FILE. The
+// files are loaded before parsing, their values are taken for the actions
+// absent from the command line and the environment.
+type ConfigAction struct {
+	*Action
+}
+
+// NewConfigAction creates a new ConfigAction.
+func NewConfigAction(argument *Argument) ActionInterface {
+	if argument.Nargs != nil {
+		panic(fmt.Sprintf("nargs not allowed for config actions: %v", argument.Nargs))
+	}
+
+	return &ConfigAction{
+		Action: &Action{
+			OptionStrings: argument.OptionStrings,
+			Dest:          argument.Dest,
+			Default:       argument.Default,
+			Required:      argument.Required,
+			Help:          argument.Help,
+			MetaVar:       argument.MetaVar,
+			Deprecated:    argument.Deprecated,
+			Completer:     argument.Completer,
+			Validate:      argument.Validate,
+		},
+	}
+}
+
+// Call appends the configuration file to the list in the namespace.
+func (a *ConfigAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	items, found := namespace.Get(a.Dest)
+	if !found {
+		items = []any{}
+	}
+	items = CopyItems(items)
+	items = append(items.([]any), values)
+	namespace.Set(a.Dest, items)
+	return nil
+}
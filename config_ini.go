@@ -0,0 +1,62 @@
+package argparse
+
+import (
+	"strings"
+)
+
+// ParseINIConfig parses an INI configuration file, dots in the section names
+// separate nested sections. The values are strings, the indented lines
+// continue the value of the previous key.
+func ParseINIConfig(data []byte) ([]ConfigEntry, error) {
+	var entries []ConfigEntry
+	var section []string
+	continuable := false
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		text := strings.TrimSpace(line)
+		if text == "" || strings.HasPrefix(text, ";") || strings.HasPrefix(text, "#") {
+			continuable = false
+			continue
+		}
+
+		// continuation lines are appended to the previous value
+		if continuable && (line[0] == ' ' || line[0] == '\t') {
+			last := &entries[len(entries)-1]
+			if value := last.Value.(string); value == "" {
+				last.Value = text
+			} else {
+				last.Value = value + "\n" + text
+			}
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, NewConfigError("", i+1, "invalid section header")
+			}
+			section = nil
+			for _, name := range strings.Split(text[1:len(text)-1], ".") {
+				section = append(section, strings.TrimSpace(name))
+			}
+			continuable = false
+			continue
+		}
+
+		separator := strings.IndexAny(text, "=:")
+		if separator <= 0 {
+			return nil, NewConfigError("", i+1, "expected 'key = value'")
+		}
+		key := strings.TrimSpace(text[:separator])
+		value := unquoteINI(strings.TrimSpace(text[separator+1:]))
+		entries = append(entries, ConfigEntry{Keys: append(append([]string{}, section...), key), Value: value, Line: i + 1})
+		continuable = true
+	}
+	return entries, nil
+}
+
+// unquoteINI removes the matching quotes around an INI value.
+func unquoteINI(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
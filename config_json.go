@@ -0,0 +1,104 @@
+package argparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ParseJSONConfig parses a JSON configuration file, an object whose nested
+// objects are sections. Arrays of scalars provide several values.
+func ParseJSONConfig(data []byte) ([]ConfigEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	// line returns the line of the last token read
+	line := func() int {
+		return bytes.Count(data[:decoder.InputOffset()], []byte("\n")) + 1
+	}
+	// syntaxError converts the decoder errors to ConfigErrors
+	syntaxError := func(err error) error {
+		var jsonErr *json.SyntaxError
+		if errors.As(err, &jsonErr) {
+			return NewConfigError("", bytes.Count(data[:jsonErr.Offset], []byte("\n"))+1, jsonErr.Error())
+		}
+		return NewConfigError("", line(), err.Error())
+	}
+
+	if token, err := decoder.Token(); err != nil {
+		return nil, syntaxError(err)
+	} else if token != json.Delim('{') {
+		return nil, NewConfigError("", line(), "expected an object")
+	}
+
+	var entries []ConfigEntry
+	var parseObject func(keys []string) error
+	parseObject = func(keys []string) error {
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return syntaxError(err)
+			}
+			keyLine := line()
+			path := append(append([]string{}, keys...), token.(string))
+
+			if token, err = decoder.Token(); err != nil {
+				return syntaxError(err)
+			}
+			switch token {
+			case json.Delim('{'):
+				if err := parseObject(path); err != nil {
+					return err
+				}
+				continue
+			case json.Delim('['):
+				values := []any{}
+				for decoder.More() {
+					if token, err = decoder.Token(); err != nil {
+						return syntaxError(err)
+					}
+					value, ok := jsonScalar(token)
+					if !ok {
+						return NewConfigError("", line(), fmt.Sprintf("nested values are not supported in array '%s'", path[len(path)-1]))
+					}
+					values = append(values, value)
+				}
+				if _, err := decoder.Token(); err != nil {
+					return syntaxError(err)
+				}
+				entries = append(entries, ConfigEntry{Keys: path, Value: values, Line: keyLine})
+			default:
+				value, _ := jsonScalar(token)
+				entries = append(entries, ConfigEntry{Keys: path, Value: value, Line: keyLine})
+			}
+		}
+
+		// the closing brace
+		if _, err := decoder.Token(); err != nil {
+			return syntaxError(err)
+		}
+		return nil
+	}
+
+	if err := parseObject(nil); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// jsonScalar converts a JSON token to a configuration value, numbers are
+// int64 if they are integers, float64 otherwise.
+func jsonScalar(token any) (any, bool) {
+	switch value := token.(type) {
+	case json.Delim:
+		return nil, false
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer, true
+		}
+		float, err := value.Float64()
+		return float, err == nil
+	}
+	return token, true
+}
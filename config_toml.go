@@ -0,0 +1,234 @@
+package argparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tomlBareKeyMatcher matches the TOML keys that need no quotes.
+var tomlBareKeyMatcher = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlDateMatcher matches the TOML dates and times, kept as strings.
+var tomlDateMatcher = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}|^\d{2}:\d{2}:\d{2}`)
+
+// ParseTOMLConfig parses a TOML configuration file, tables are sections.
+// Strings, numbers, booleans, dates (as strings) and arrays of them are
+// supported, inline tables, arrays of tables and multi-line strings are not.
+func ParseTOMLConfig(data []byte) ([]ConfigEntry, error) {
+	var entries []ConfigEntry
+	var table []string
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(stripComment(lines[i], "#"))
+		if line == "" {
+			continue
+		}
+
+		// table headers start a section
+		if strings.HasPrefix(line, "[[") {
+			return nil, NewConfigError("", lineNumber, "arrays of tables are not supported")
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, NewConfigError("", lineNumber, "invalid table header")
+			}
+			keys, err := parseTOMLKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, NewConfigError("", lineNumber, err.Error())
+			}
+			table = keys
+			continue
+		}
+
+		// key = value
+		separator := indexUnquoted(line, '=')
+		if separator < 0 {
+			return nil, NewConfigError("", lineNumber, "expected 'key = value'")
+		}
+		keys, err := parseTOMLKey(line[:separator])
+		if err != nil {
+			return nil, NewConfigError("", lineNumber, err.Error())
+		}
+		valueString := strings.TrimSpace(line[separator+1:])
+
+		// arrays may continue on the following lines
+		for strings.HasPrefix(valueString, "[") && !balancedBrackets(valueString) && i+1 < len(lines) {
+			i++
+			valueString += " " + strings.TrimSpace(stripComment(lines[i], "#"))
+		}
+		value, err := parseTOMLValue(valueString)
+		if err != nil {
+			return nil, NewConfigError("", lineNumber, err.Error())
+		}
+		entries = append(entries, ConfigEntry{Keys: append(append([]string{}, table...), keys...), Value: value, Line: lineNumber})
+	}
+	return entries, nil
+}
+
+// parseTOMLKey parses a dotted TOML key of bare or quoted parts.
+func parseTOMLKey(key string) ([]string, error) {
+	var keys []string
+	for _, part := range splitUnquoted(key, '.') {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, `"`):
+			unquoted, err := strconv.Unquote(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key: %s", key)
+			}
+			part = unquoted
+		case strings.HasPrefix(part, "'") && strings.HasSuffix(part, "'") && len(part) > 1:
+			part = part[1 : len(part)-1]
+		case !tomlBareKeyMatcher.MatchString(part):
+			return nil, fmt.Errorf("invalid key: %s", strings.TrimSpace(key))
+		}
+		keys = append(keys, part)
+	}
+	return keys, nil
+}
+
+// parseTOMLValue parses a TOML value.
+func parseTOMLValue(value string) (any, error) {
+	switch {
+	case value == "":
+		return nil, fmt.Errorf("missing value")
+	case strings.HasPrefix(value, `"""`) || strings.HasPrefix(value, "'''"):
+		return nil, fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string: %s", value)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") || strings.Contains(value[1:len(value)-1], "'") {
+			return nil, fmt.Errorf("invalid string: %s", value)
+		}
+		return value[1 : len(value)-1], nil
+	case strings.HasPrefix(value, "{"):
+		return nil, fmt.Errorf("inline tables are not supported")
+	case strings.HasPrefix(value, "["):
+		if !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("invalid array: %s", value)
+		}
+		values := []any{}
+		for _, item := range splitUnquoted(value[1:len(value)-1], ',') {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if strings.HasPrefix(item, "[") {
+				return nil, fmt.Errorf("nested arrays are not supported")
+			}
+			parsed, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, parsed)
+		}
+		return values, nil
+	case value == "true":
+		return true, nil
+	case value == "false":
+		return false, nil
+	case tomlDateMatcher.MatchString(value):
+		return value, nil
+	}
+
+	number := strings.ReplaceAll(value, "_", "")
+	if integer, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return integer, nil
+	}
+	if float, err := strconv.ParseFloat(number, 64); err == nil {
+		return float, nil
+	}
+	return nil, fmt.Errorf("invalid value: %s", value)
+}
+
+// stripComment removes the comment starting with one of chars outside of
+// quotes from line.
+func stripComment(line string, chars string) string {
+	if index := indexUnquotedAny(line, chars); index >= 0 {
+		return line[:index]
+	}
+	return line
+}
+
+// indexUnquoted returns the index of the first c outside of quotes in s, or -1.
+func indexUnquoted(s string, c byte) int {
+	return indexUnquotedAny(s, string(c))
+}
+
+// indexUnquotedAny returns the index of the first of chars outside of quotes
+// in s, or -1. Double-quoted strings may contain backslash escapes.
+func indexUnquotedAny(s string, chars string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.IndexByte(chars, s[i]) >= 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnquoted splits s at the separators outside of quotes and brackets.
+func splitUnquoted(s string, separator byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '[' || s[i] == '{':
+			depth++
+		case s[i] == ']' || s[i] == '}':
+			depth--
+		case s[i] == separator && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// balancedBrackets reports whether the brackets outside of quotes in s are balanced.
+func balancedBrackets(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '[':
+			depth++
+		case s[i] == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
@@ -0,0 +1,191 @@
+package argparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlBoolMatcher matches the YAML booleans.
+var yamlBoolMatcher = regexp.MustCompile(`^(?i:true|false|yes|no|on|off)$`)
+
+// yamlLine is a significant line of a YAML file.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// ParseYAMLConfig parses a YAML configuration file, nested mappings are
+// sections. Scalars, block and flow sequences of scalars are supported,
+// flow mappings, block scalars, anchors and multiple documents are not.
+func ParseYAMLConfig(data []byte) ([]ConfigEntry, error) {
+	var lines []yamlLine
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		content := strings.TrimRight(stripYAMLComment(line), " ")
+		text := strings.TrimLeft(content, " ")
+		if text == "" || (i == 0 && text == "---") {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, NewConfigError("", i+1, "tabs are not allowed for indentation")
+		}
+		if text == "---" || text == "..." {
+			return nil, NewConfigError("", i+1, "multiple documents are not supported")
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(content) - len(text), text: text})
+	}
+
+	var entries []ConfigEntry
+	index := 0
+	var parseMapping func(keys []string, indent int) error
+	parseMapping = func(keys []string, indent int) error {
+		for index < len(lines) && lines[index].indent >= indent {
+			line := lines[index]
+			if line.indent > indent {
+				return NewConfigError("", line.number, "unexpected indentation")
+			}
+			if strings.HasPrefix(line.text, "- ") || line.text == "-" {
+				return NewConfigError("", line.number, "unexpected sequence item")
+			}
+			separator := strings.Index(line.text+" ", ": ")
+			if quoted := strings.HasPrefix(line.text, `"`) || strings.HasPrefix(line.text, "'"); quoted {
+				separator = indexUnquoted(line.text+" ", ':')
+			}
+			if separator < 0 {
+				return NewConfigError("", line.number, "expected 'key: value'")
+			}
+			key, err := parseYAMLScalar(strings.TrimSpace(line.text[:separator]))
+			if err != nil {
+				return NewConfigError("", line.number, err.Error())
+			}
+			path := append(append([]string{}, keys...), configString(key))
+			valueString := strings.TrimSpace((line.text + " ")[separator+1:])
+			index++
+
+			if valueString != "" {
+				value, err := parseYAMLValue(valueString)
+				if err != nil {
+					return NewConfigError("", line.number, err.Error())
+				}
+				entries = append(entries, ConfigEntry{Keys: path, Value: value, Line: line.number})
+				continue
+			}
+
+			// a block sequence, it may have the indentation of the key
+			if index < len(lines) && lines[index].indent >= indent && (strings.HasPrefix(lines[index].text, "- ") || lines[index].text == "-") {
+				itemIndent := lines[index].indent
+				values := []any{}
+				for index < len(lines) && lines[index].indent == itemIndent && (strings.HasPrefix(lines[index].text, "- ") || lines[index].text == "-") {
+					item := strings.TrimSpace(lines[index].text[1:])
+					value, err := parseYAMLValue(item)
+					if err != nil {
+						return NewConfigError("", lines[index].number, err.Error())
+					}
+					if _, nested := value.([]any); nested || item == "" {
+						return NewConfigError("", lines[index].number, "nested values are not supported in sequences")
+					}
+					values = append(values, value)
+					index++
+				}
+				entries = append(entries, ConfigEntry{Keys: path, Value: values, Line: line.number})
+				continue
+			}
+
+			// a nested mapping, or a null value
+			if index < len(lines) && lines[index].indent > indent {
+				if err := parseMapping(path, lines[index].indent); err != nil {
+					return err
+				}
+				continue
+			}
+			entries = append(entries, ConfigEntry{Keys: path, Value: nil, Line: line.number})
+		}
+		return nil
+	}
+
+	if err := parseMapping(nil, 0); err != nil {
+		return nil, err
+	}
+	if index < len(lines) {
+		return nil, NewConfigError("", lines[index].number, "unexpected indentation")
+	}
+	return entries, nil
+}
+
+// stripYAMLComment removes the comment from a YAML line, comments start
+// with a # at the start of the line or after a space.
+func stripYAMLComment(line string) string {
+	for start := 0; ; {
+		index := indexUnquoted(line[start:], '#')
+		if index < 0 {
+			return line
+		}
+		index += start
+		if index == 0 || line[index-1] == ' ' || line[index-1] == '\t' {
+			return line[:index]
+		}
+		start = index + 1
+	}
+}
+
+// parseYAMLValue parses a YAML scalar or flow sequence.
+func parseYAMLValue(value string) (any, error) {
+	switch {
+	case strings.HasPrefix(value, "{"):
+		return nil, fmt.Errorf("flow mappings are not supported")
+	case strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+		return nil, fmt.Errorf("block scalars are not supported")
+	case strings.HasPrefix(value, "&") || strings.HasPrefix(value, "*"):
+		return nil, fmt.Errorf("anchors and aliases are not supported")
+	case strings.HasPrefix(value, "["):
+		if !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("invalid flow sequence: %s", value)
+		}
+		values := []any{}
+		for _, item := range splitUnquoted(value[1:len(value)-1], ',') {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if strings.HasPrefix(item, "[") || strings.HasPrefix(item, "{") {
+				return nil, fmt.Errorf("nested values are not supported in sequences")
+			}
+			parsed, err := parseYAMLScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, parsed)
+		}
+		return values, nil
+	}
+	return parseYAMLScalar(value)
+}
+
+// parseYAMLScalar parses a plain or quoted YAML scalar.
+func parseYAMLScalar(value string) (any, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string: %s", value)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return nil, fmt.Errorf("invalid string: %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	case value == "" || value == "~" || value == "null" || value == "Null" || value == "NULL":
+		return nil, nil
+	case yamlBoolMatcher.MatchString(value):
+		return parseBool(value)
+	}
+	if integer, err := strconv.ParseInt(value, 0, 64); err == nil {
+		return integer, nil
+	}
+	if float, err := strconv.ParseFloat(value, 64); err == nil {
+		return float, nil
+	}
+	return value, nil
+}
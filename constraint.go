@@ -0,0 +1,179 @@
+package argparse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The kinds of constraints between arguments.
+const (
+	CONSTRAINT_REQUIRES     = "requires"
+	CONSTRAINT_CONFLICTS    = "conflicts"
+	CONSTRAINT_REQUIRED_IF  = "required if"
+	CONSTRAINT_AT_LEAST_ONE = "at least one"
+	CONSTRAINT_ALL_OR_NONE  = "all or none"
+)
+
+// Constraint is a relationship between arguments checked after parsing,
+// beyond the ones expressed by mutually exclusive groups. An argument is
+// present if it was given on the command line, in the environment or in a
+// configuration file.
+type Constraint struct {
+	Kind    string            // One of the CONSTRAINT_ constants
+	Actions []ActionInterface // The constrained arguments
+	Targets []ActionInterface // The arguments required by or conflicting with Actions[0], or the condition of required if
+	Value   any               // The value of the condition of required if
+}
+
+// Requires adds a constraint that action can only be given with all of the
+// required arguments, e.g. --cert requires --key.
+func (ap *ArgumentParser) Requires(action ActionInterface, required ...ActionInterface) *Constraint {
+	return ap.addConstraint(&Constraint{Kind: CONSTRAINT_REQUIRES, Actions: []ActionInterface{action}, Targets: required})
+}
+
+// Conflicts adds a constraint that action can't be given with any of the
+// conflicting arguments, e.g. --dry-run conflicts with --force.
+func (ap *ArgumentParser) Conflicts(action ActionInterface, conflicting ...ActionInterface) *Constraint {
+	return ap.addConstraint(&Constraint{Kind: CONSTRAINT_CONFLICTS, Actions: []ActionInterface{action}, Targets: conflicting})
+}
+
+// RequiredIf adds a constraint that action is required if the value of
+// condition equals value, e.g. --output is required if --format is "file".
+func (ap *ArgumentParser) RequiredIf(action ActionInterface, condition ActionInterface, value any) *Constraint {
+	return ap.addConstraint(&Constraint{Kind: CONSTRAINT_REQUIRED_IF, Actions: []ActionInterface{action}, Targets: []ActionInterface{condition}, Value: value})
+}
+
+// AtLeastOne adds a constraint that at least one of actions is given.
+func (ap *ArgumentParser) AtLeastOne(actions ...ActionInterface) *Constraint {
+	return ap.addConstraint(&Constraint{Kind: CONSTRAINT_AT_LEAST_ONE, Actions: actions})
+}
+
+// AllOrNone adds a constraint that either all of actions or none of them
+// are given, e.g. --user and --password.
+func (ap *ArgumentParser) AllOrNone(actions ...ActionInterface) *Constraint {
+	return ap.addConstraint(&Constraint{Kind: CONSTRAINT_ALL_OR_NONE, Actions: actions})
+}
+
+// addConstraint adds constraint to the constraints of the parser.
+func (ap *ArgumentParser) addConstraint(constraint *Constraint) *Constraint {
+	if len(constraint.Actions) == 0 {
+		panic(fmt.Sprintf("%s constraint without actions", constraint.Kind))
+	}
+	for _, actions := range [][]ActionInterface{constraint.Actions, constraint.Targets} {
+		for _, action := range actions {
+			if action == nil {
+				panic(fmt.Sprintf("%s constraint on a nil action", constraint.Kind))
+			}
+		}
+	}
+	ap.Constraints = append(ap.Constraints, constraint)
+	return constraint
+}
+
+// Check returns an error if the constraint is not satisfied by namespace,
+// present reports whether an action was given.
+func (c *Constraint) Check(namespace *Namespace, present func(ActionInterface) bool) error {
+	action := c.Actions[0]
+	switch c.Kind {
+	case CONSTRAINT_REQUIRES:
+		if !present(action) {
+			return nil
+		}
+		var missing []ActionInterface
+		for _, target := range c.Targets {
+			if !present(target) {
+				missing = append(missing, target)
+			}
+		}
+		if len(missing) == 1 {
+			return NewArgumentError(action.Struct(), fmt.Sprintf("requires argument %s", actionNames(missing, ", ")))
+		} else if len(missing) > 1 {
+			return NewArgumentError(action.Struct(), fmt.Sprintf("requires arguments %s", actionNames(missing, ", ")))
+		}
+
+	case CONSTRAINT_CONFLICTS:
+		if !present(action) {
+			return nil
+		}
+		for _, target := range c.Targets {
+			if present(target) {
+				return NewArgumentError(action.Struct(), fmt.Sprintf("not allowed with argument %s", GetActionName(target.Struct())))
+			}
+		}
+
+	case CONSTRAINT_REQUIRED_IF:
+		condition := c.Targets[0].Struct()
+		if value, _ := namespace.Get(condition.Dest); !present(action) && reflect.DeepEqual(value, c.Value) {
+			return NewArgumentError(action.Struct(), fmt.Sprintf("required when %s is %s", GetActionName(condition), formatValue(c.Value)))
+		}
+
+	case CONSTRAINT_AT_LEAST_ONE:
+		for _, action := range c.Actions {
+			if present(action) {
+				return nil
+			}
+		}
+		return NewArgumentError(nil, fmt.Sprintf("at least one of the arguments %s is required", actionNames(c.Actions, " ")))
+
+	case CONSTRAINT_ALL_OR_NONE:
+		var given, missing []ActionInterface
+		for _, action := range c.Actions {
+			if present(action) {
+				given = append(given, action)
+			} else {
+				missing = append(missing, action)
+			}
+		}
+		if len(given) > 0 && len(missing) > 0 {
+			return NewArgumentError(nil, fmt.Sprintf("the arguments %s must be given together, missing: %s", actionNames(c.Actions, " "), actionNames(missing, ", ")))
+		}
+	}
+	return nil
+}
+
+// Note returns the note on the constraint in the help of action, e.g.
+// "requires --key", or "" if the constraint doesn't involve action.
+func (c *Constraint) Note(action ActionInterface) string {
+	switch c.Kind {
+	case CONSTRAINT_REQUIRES:
+		if c.Actions[0] == action {
+			return "requires " + actionNames(c.Targets, ", ")
+		}
+	case CONSTRAINT_CONFLICTS:
+		if c.Actions[0] == action {
+			return "conflicts with " + actionNames(c.Targets, ", ")
+		}
+		if containsAction(c.Targets, action) {
+			return "conflicts with " + GetActionName(c.Actions[0].Struct())
+		}
+	case CONSTRAINT_REQUIRED_IF:
+		if c.Actions[0] == action {
+			return fmt.Sprintf("required if %s is %s", GetActionName(c.Targets[0].Struct()), formatValue(c.Value))
+		}
+	case CONSTRAINT_AT_LEAST_ONE, CONSTRAINT_ALL_OR_NONE:
+		if containsAction(c.Actions, action) {
+			return fmt.Sprintf("%s of %s", c.Kind, actionNames(c.Actions, ", "))
+		}
+	}
+	return ""
+}
+
+// actionNames joins the names of actions with separator.
+func actionNames(actions []ActionInterface, separator string) string {
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = GetActionName(action.Struct())
+	}
+	return strings.Join(names, separator)
+}
+
+// containsAction reports whether actions contains action.
+func containsAction(actions []ActionInterface, action ActionInterface) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
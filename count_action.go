@@ -16,6 +16,9 @@ func NewCountAction(argument *Argument) ActionInterface {
 			Required:      argument.Required,
 			Help:          argument.Help,
 			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
+			Prompt:        argument.Prompt,
+			PromptHidden:  argument.PromptHidden,
 		},
 	}
 }
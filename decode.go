@@ -0,0 +1,271 @@
+package argparse
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DecodeError represents a value of a Namespace that can't be stored in a
+// struct field by Decode.
+type DecodeError struct {
+	Dest    string // The dest of the value
+	Field   string // The path of the field, e.g. "Config.Ports[1]"
+	Message string
+}
+
+// Error implements the error interface for DecodeError.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cannot decode '%s' into %s: %s", e.Dest, e.Field, e.Message)
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decode stores the values of the namespace in the fields of the struct
+// target points to. A field receives the value of the dest named by its
+// `argparse:"dest"` tag, or else of the dest matching its name ignoring
+// case and underscores, e.g. LogLevel for log_level. The tags of AddStruct
+// are understood too: an argparse tag of option strings, e.g. "-v,--verbose",
+// names the dest the parser infers from them unless a dest tag names it.
+// Fields tagged "-",
+// unexported fields and fields without a dest are left unchanged, the
+// fields of embedded structs are decoded as fields of target.
+//
+// Integers and floats are converted to the width of the field, slices and
+// maps with string keys element by element, strings to time.Duration or
+// with UnmarshalText, and pointer fields are nil for nil values.
+func (n *Namespace) Decode(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a non-nil pointer to a struct, got %T", target)
+	}
+	return n.decodeStruct(value.Elem(), value.Elem().Type().Name())
+}
+
+// decodeStruct decodes the namespace into the fields of the struct value.
+func (n *Namespace) decodeStruct(value reflect.Value, path string) error {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("argparse")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		// the fields of embedded structs are promoted
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			if err := n.decodeStruct(value.Field(i), path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		dest, found := n.fieldDest(field)
+		if !found {
+			continue
+		}
+		if err := decodeValue(n.attributes[dest], value.Field(i), path+"."+field.Name); err != nil {
+			err.Dest = dest
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldDest returns the dest decoded into field.
+func (n *Namespace) fieldDest(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("argparse")
+	if tag != "" && isPrefixRune(rune(tag[0])) {
+		tag = field.Tag.Get("dest")
+		if tag == "" {
+			tag = optionStringsDest(strings.Split(field.Tag.Get("argparse"), ","))
+		}
+	}
+	if tag != "" {
+		_, found := n.attributes[tag]
+		return tag, found
+	}
+	name := field.Name
+
+	dests := make([]string, 0, len(n.attributes))
+	for dest := range n.attributes {
+		dests = append(dests, dest)
+	}
+	sort.Strings(dests)
+	for _, dest := range dests {
+		if normalizeName(dest) == normalizeName(name) {
+			return dest, true
+		}
+	}
+	return "", false
+}
+
+// optionStringsDest returns the dest inferred from option strings as by
+// GetOptionalArgument, '--foo-bar' -> 'foo_bar' and '-x' -> 'x'.
+func optionStringsDest(optionStrings []string) string {
+	destOptionString := optionStrings[0]
+	for _, optionString := range optionStrings {
+		if len(optionString) > 1 && isPrefixRune(rune(optionString[1])) {
+			destOptionString = optionString
+			break
+		}
+	}
+	return strings.ReplaceAll(strings.TrimLeftFunc(destOptionString, isPrefixRune), "-", "_")
+}
+
+// isPrefixRune reports whether r can't start a dest, e.g. a prefix character.
+func isPrefixRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// normalizeName lowercases name and removes its underscores and dashes.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// decodeValue stores value in target, converting it to the type of target.
+func decodeValue(value any, target reflect.Value, path string) *DecodeError {
+	targetType := target.Type()
+	if value == nil {
+		target.Set(reflect.Zero(targetType))
+		return nil
+	}
+	source := reflect.ValueOf(value)
+	mismatch := func() *DecodeError {
+		return &DecodeError{Field: path, Message: fmt.Sprintf("%T value %s is not compatible with %s", value, formatDecodeValue(value), targetType)}
+	}
+
+	if source.Type().AssignableTo(targetType) {
+		target.Set(source)
+		return nil
+	}
+
+	// strings are parsed by the types that know how to
+	if text, ok := value.(string); ok {
+		if targetType == durationType {
+			duration, err := time.ParseDuration(text)
+			if err != nil {
+				return &DecodeError{Field: path, Message: err.Error()}
+			}
+			target.SetInt(int64(duration))
+			return nil
+		}
+		if reflect.PointerTo(targetType).Implements(textUnmarshalerType) {
+			if err := target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+				return &DecodeError{Field: path, Message: err.Error()}
+			}
+			return nil
+		}
+	}
+
+	switch targetType.Kind() {
+	case reflect.Pointer:
+		element := reflect.New(targetType.Elem())
+		if err := decodeValue(value, element.Elem(), path); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var integer int64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			integer = source.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if source.Uint() > 1<<63-1 {
+				return &DecodeError{Field: path, Message: fmt.Sprintf("value %d overflows %s", value, targetType)}
+			}
+			integer = int64(source.Uint())
+		default:
+			return mismatch()
+		}
+		if target.OverflowInt(integer) {
+			return &DecodeError{Field: path, Message: fmt.Sprintf("value %d overflows %s", value, targetType)}
+		}
+		target.SetInt(integer)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var integer uint64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if source.Int() < 0 {
+				return &DecodeError{Field: path, Message: fmt.Sprintf("negative value %d for %s", value, targetType)}
+			}
+			integer = uint64(source.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			integer = source.Uint()
+		default:
+			return mismatch()
+		}
+		if target.OverflowUint(integer) {
+			return &DecodeError{Field: path, Message: fmt.Sprintf("value %d overflows %s", value, targetType)}
+		}
+		target.SetUint(integer)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch source.Kind() {
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(source.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			target.SetFloat(float64(source.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			target.SetFloat(float64(source.Uint()))
+		default:
+			return mismatch()
+		}
+		return nil
+
+	case reflect.Slice:
+		if source.Kind() != reflect.Slice && source.Kind() != reflect.Array {
+			return mismatch()
+		}
+		slice := reflect.MakeSlice(targetType, source.Len(), source.Len())
+		for i := 0; i < source.Len(); i++ {
+			if err := decodeValue(source.Index(i).Interface(), slice.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+
+	case reflect.Map:
+		if source.Kind() != reflect.Map || targetType.Key().Kind() != reflect.String || source.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		mapping := reflect.MakeMapWithSize(targetType, source.Len())
+		for _, key := range source.MapKeys() {
+			element := reflect.New(targetType.Elem()).Elem()
+			if err := decodeValue(source.MapIndex(key).Interface(), element, fmt.Sprintf("%s[%q]", path, key.String())); err != nil {
+				return err
+			}
+			mapping.SetMapIndex(reflect.ValueOf(key.String()).Convert(targetType.Key()), element)
+		}
+		target.Set(mapping)
+		return nil
+	}
+
+	// the other values must be convertible without loss, e.g. named types
+	if source.Kind() == targetType.Kind() && source.Type().ConvertibleTo(targetType) {
+		target.Set(source.Convert(targetType))
+		return nil
+	}
+	return mismatch()
+}
+
+// formatDecodeValue formats a value for a DecodeError message, strings are quoted.
+func formatDecodeValue(value any) string {
+	if text, ok := value.(string); ok {
+		return fmt.Sprintf("%q", text)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,433 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// COMPLETE_ENV is the environment variable switching parsing to the hidden
+// completion entrypoint, its value is the shell asking for candidates.
+//
+// The dynamic completion scripts run the program with COMPLETE_ENV set and
+// the words typed so far as arguments, the last one being the prefix to
+// complete. The program prints the candidates, one per line, and exits.
+const COMPLETE_ENV = "ARGPARSE_COMPLETE"
+
+// Candidate is a completion candidate with an optional description.
+type Candidate struct {
+	Value       string
+	Description string
+}
+
+// CompleterFunc returns the completion candidates for an argument, given the
+// prefix typed so far and the namespace of the partially parsed command line.
+type CompleterFunc = func(prefix string, ns *Namespace) []Candidate
+
+// Complete returns the completion candidates for the last of args. The
+// preceding args are partially parsed to determine the action being
+// completed. The actions are not taken and their types not called, the
+// namespace passed to the completers holds the strings typed so far.
+func (ap *ArgumentParser) Complete(args []string) []Candidate {
+	prefix := ""
+	if len(args) > 0 {
+		prefix = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	return ap.complete(args, prefix, NewNamespace(map[string]any{}))
+}
+
+// nargsRange returns the minimum and maximum number of arg strings consumed
+// by action, a maximum of -1 means unlimited.
+func nargsRange(action ActionInterface) (int, int) {
+	switch nargs := action.Struct().Nargs.(type) {
+	case nil:
+		return 1, 1
+	case int:
+		return nargs, nargs
+	}
+	switch action.Struct().Nargs {
+	case OPTIONAL:
+		return 0, 1
+	case ZERO_OR_MORE, REMAINDER:
+		return 0, -1
+	case ONE_OR_MORE, PARSER:
+		return 1, -1
+	}
+	return 0, 0
+}
+
+// recordCompletionValues records the arg strings of action in namespace as
+// typed: a flag stores its const, a count action counts and the append and
+// extend actions accumulate. Types, which may open files or read stdin, and
+// the Call of the actions are never run while completing.
+func recordCompletionValues(action ActionInterface, argStrings []string, optionString string, namespace *Namespace) {
+	act := action.Struct()
+	if act.Dest == SUPPRESS {
+		return
+	}
+	current, _ := namespace.Get(act.Dest)
+	list, _ := current.([]any)
+
+	switch a := action.(type) {
+	case *HelpAction, *VersionAction, *SubParsersAction:
+		return
+	case *BooleanOptionalAction:
+		namespace.Set(act.Dest, !a.negatives[optionString])
+		return
+	case *CountAction:
+		count, _ := current.(int)
+		namespace.Set(act.Dest, count+1)
+		return
+	case *AppendConstAction:
+		namespace.Set(act.Dest, append(list[:len(list):len(list)], act.Const))
+		return
+	}
+
+	var values any
+	switch {
+	case len(argStrings) == 0:
+		if act.Const == nil {
+			return
+		}
+		values = act.Const
+	case act.Nargs == nil || act.Nargs == OPTIONAL:
+		values = argStrings[0]
+	default:
+		items := make([]any, len(argStrings))
+		for i, argString := range argStrings {
+			items[i] = argString
+		}
+		values = items
+	}
+
+	switch action.(type) {
+	case *ExtendAction:
+		if items, ok := values.([]any); ok {
+			namespace.Set(act.Dest, append(list[:len(list):len(list)], items...))
+			return
+		}
+		namespace.Set(act.Dest, append(list[:len(list):len(list)], values))
+	case *AppendAction:
+		namespace.Set(act.Dest, append(list[:len(list):len(list)], values))
+	default:
+		namespace.Set(act.Dest, values)
+	}
+}
+
+func (ap *ArgumentParser) complete(args []string, prefix string, namespace *Namespace) []Candidate {
+	ap.setNamespaceDefaults(namespace)
+
+	positionals := ap.GetPositionalActions()
+	var pending ActionInterface
+	var pendingArgs []string
+	var pendingOption string
+	dashDash := false
+
+	// record the arg strings of the pending action, without converting them
+	takePending := func() {
+		if pending == nil {
+			return
+		}
+		recordCompletionValues(pending, pendingArgs, pendingOption, namespace)
+		pending, pendingArgs, pendingOption = nil, nil, ""
+	}
+
+	for i, arg := range args {
+		// REMAINDER options consume everything
+		if pending != nil && pending.Struct().Nargs == REMAINDER {
+			pendingArgs = append(pendingArgs, arg)
+			continue
+		}
+
+		if !dashDash && arg == "--" {
+			takePending()
+			dashDash = true
+			continue
+		}
+
+		if !dashDash {
+			if optionTuples, _ := ap.ParseOptional_(arg); optionTuples != nil {
+				takePending()
+				if len(optionTuples) == 1 && optionTuples[0].Action != nil {
+					optionTuple := optionTuples[0]
+					pending = optionTuple.Action
+					pendingOption = optionTuple.OptionString
+					if _, maxArgs := nargsRange(pending); optionTuple.ExplicitArg != nil && maxArgs != 0 {
+						pendingArgs = []string{*optionTuple.ExplicitArg}
+						takePending()
+					} else if maxArgs == 0 {
+						takePending()
+					}
+				}
+				continue
+			}
+		}
+
+		// an argument of the pending option or positional
+		if pending != nil {
+			if _, maxArgs := nargsRange(pending); maxArgs == -1 || len(pendingArgs) < maxArgs {
+				pendingArgs = append(pendingArgs, arg)
+				if len(pendingArgs) == maxArgs {
+					takePending()
+				}
+				continue
+			}
+			takePending()
+		}
+
+		// the next positional, extra arguments are ignored
+		if len(positionals) == 0 {
+			continue
+		}
+		action := positionals[0]
+		positionals = positionals[1:]
+
+		// continue with the subparser, it completes the remaining args
+		if subparsers, ok := action.(*SubParsersAction); ok {
+			subparser, found := subparsers.NameParserMap[arg]
+			if !found {
+				return nil
+			}
+			if subparsers.Dest != SUPPRESS {
+				namespace.Set(subparsers.Dest, arg)
+			}
+			return subparser.complete(args[i+1:], prefix, namespace)
+		}
+
+		pending = action
+		pendingArgs = []string{arg}
+		if _, maxArgs := nargsRange(pending); len(pendingArgs) == maxArgs {
+			takePending()
+		}
+	}
+
+	looksLikeOption := !dashDash && prefix != "" && strings.Contains(ap.PrefixChars, prefix[:1])
+
+	// complete the values of the pending option or positional
+	if pending != nil {
+		minArgs, maxArgs := nargsRange(pending)
+		if len(pendingArgs) < minArgs || (maxArgs == -1 || len(pendingArgs) < maxArgs) && !looksLikeOption {
+			return ap.completeValues(pending, prefix, namespace)
+		}
+		takePending()
+	}
+
+	if looksLikeOption {
+		// complete the value of an option with an explicit argument, e.g. --color=re
+		if optionString, value, found := strings.Cut(prefix, "="); found {
+			if action, found := ap.OptionStringActions[optionString]; found {
+				candidates := ap.completeValues(action, value, namespace)
+				for i := range candidates {
+					candidates[i].Value = optionString + "=" + candidates[i].Value
+				}
+				return candidates
+			}
+		}
+		return ap.completeOptions(prefix)
+	}
+
+	// complete the next positional, offer options if there are none left
+	if len(positionals) > 0 {
+		if subparsers, ok := positionals[0].(*SubParsersAction); ok {
+			return completeSubcommands(subparsers, prefix)
+		}
+		return ap.completeValues(positionals[0], prefix, namespace)
+	}
+	if dashDash {
+		return nil
+	}
+	return ap.completeOptions(prefix)
+}
+
+// completeValues returns the candidates for the values of action, using its
+// Completer, its Choices or file names for a FileType or a config action.
+func (ap *ArgumentParser) completeValues(action ActionInterface, prefix string, namespace *Namespace) []Candidate {
+	act := action.Struct()
+	var candidates []Candidate
+	if act.Completer != nil {
+		candidates = act.Completer(prefix, namespace)
+	} else if act.Choices != nil {
+		for _, choice := range act.Choices {
+			candidates = append(candidates, Candidate{Value: fmt.Sprintf("%v", choice)})
+		}
+	} else if _, ok := act.Type.(*FileType); ok {
+		candidates = completeFiles(prefix)
+	} else if _, ok := action.(*ConfigAction); ok {
+		candidates = completeFiles(prefix)
+	}
+	return filterCandidates(candidates, prefix)
+}
+
+// completeOptions returns the option strings starting with prefix, options
+// with help SUPPRESS and deprecated options are not offered.
+func (ap *ArgumentParser) completeOptions(prefix string) []Candidate {
+	var candidates []Candidate
+	for _, optionString := range ap.optionStrings() {
+		action := ap.OptionStringActions[optionString].Struct()
+		if action.Help == SUPPRESS || action.Deprecated {
+			continue
+		}
+		candidates = append(candidates, Candidate{Value: optionString, Description: singleLine(action.Help)})
+	}
+	return filterCandidates(candidates, prefix)
+}
+
+// completeSubcommands returns the subcommand names starting with prefix,
+// deprecated subcommands are not offered.
+func completeSubcommands(subparsers *SubParsersAction, prefix string) []Candidate {
+	helps := make(map[string]string)
+	for _, choiceAction := range subparsers.ChoicesActions {
+		helps[choiceAction.Struct().Dest] = choiceAction.Struct().Help
+	}
+
+	var candidates []Candidate
+	for _, choice := range subparsers.Choices {
+		name := fmt.Sprintf("%v", choice)
+		if _, deprecated := subparsers.Deprecated[name]; deprecated {
+			continue
+		}
+		candidates = append(candidates, Candidate{Value: name, Description: singleLine(helps[name])})
+	}
+	return filterCandidates(candidates, prefix)
+}
+
+// completeFiles returns the file names starting with prefix, directories
+// end with a path separator. Hidden files are only offered for a "." prefix.
+func completeFiles(prefix string) []Candidate {
+	dir, base := filepath.Split(prefix)
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	var candidates []Candidate
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		value := dir + name
+		if entry.IsDir() {
+			value += string(filepath.Separator)
+		}
+		candidates = append(candidates, Candidate{Value: value})
+	}
+	return candidates
+}
+
+// filterCandidates returns the candidates starting with prefix.
+func filterCandidates(candidates []Candidate, prefix string) []Candidate {
+	filtered := []Candidate{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate.Value, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+// FormatCandidates formats the candidates for the dynamic completion script
+// of shell, one candidate per line.
+func FormatCandidates(candidates []Candidate, shell string) string {
+	var b strings.Builder
+	for _, candidate := range candidates {
+		description := singleLine(candidate.Description)
+		switch shell {
+		case BASH:
+			b.WriteString(candidate.Value)
+		case ZSH:
+			b.WriteString(strings.ReplaceAll(candidate.Value, ":", `\:`))
+			if description != "" {
+				b.WriteString(":" + description)
+			}
+		default:
+			b.WriteString(candidate.Value)
+			if description != "" {
+				b.WriteString("\t" + description)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// FormatDynamicCompletion generates a completion script for the given shell,
+// one of BASH, ZSH, FISH or POWERSHELL, calling back into the program through
+// the COMPLETE_ENV entrypoint, so that Completer functions are used.
+func (ap *ArgumentParser) FormatDynamicCompletion(shell string) (string, error) {
+	var b strings.Builder
+	name := ap.Prog
+	function := "_" + completionIdentifier(name) + "_dynamic_completion"
+
+	switch shell {
+	case BASH:
+		fmt.Fprintf(&b, "# bash dynamic completion for %s, generated by argparse\n\n", name)
+		fmt.Fprintf(&b, "%s() {\n", function)
+		b.WriteString("    local IFS=$'\\n'\n")
+		fmt.Fprintf(&b, "    COMPREPLY=($(%s=bash \"${COMP_WORDS[0]}\" \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null))\n", COMPLETE_ENV)
+		b.WriteString("    if [[ ${#COMPREPLY[@]} -eq 1 && \"${COMPREPLY[0]}\" == */ ]]; then\n")
+		b.WriteString("        compopt -o nospace\n")
+		b.WriteString("    fi\n")
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "complete -F %s %s\n", function, shellQuote(name))
+	case ZSH:
+		fmt.Fprintf(&b, "#compdef %s\n\n", name)
+		fmt.Fprintf(&b, "# zsh dynamic completion for %s, generated by argparse\n\n", name)
+		fmt.Fprintf(&b, "%s() {\n", function)
+		b.WriteString("    local -a candidates\n")
+		fmt.Fprintf(&b, "    candidates=(\"${(@f)$(%s=zsh \"${words[1]}\" \"${(@)words[2,CURRENT]}\" 2>/dev/null)}\")\n", COMPLETE_ENV)
+		b.WriteString("    candidates=(${candidates:#})\n")
+		b.WriteString("    _describe 'values' candidates\n")
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "compdef %s %s\n", function, shellQuote(name))
+	case FISH:
+		function = "__fish" + function
+		fmt.Fprintf(&b, "# fish dynamic completion for %s, generated by argparse\n\n", name)
+		fmt.Fprintf(&b, "function %s\n", function)
+		b.WriteString("    set -l tokens (commandline -opc)\n")
+		b.WriteString("    set -l current (commandline -ct)\n")
+		fmt.Fprintf(&b, "    env %s=fish $tokens[1] $tokens[2..-1] \"$current\" 2>/dev/null\n", COMPLETE_ENV)
+		b.WriteString("end\n\n")
+		fmt.Fprintf(&b, "complete -c %s -f -a '(%s)'\n", fishQuote(name), function)
+	case POWERSHELL:
+		fmt.Fprintf(&b, "# powershell dynamic completion for %s, generated by argparse\n\n", name)
+		fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", powerShellQuote(name))
+		b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+		b.WriteString("    $words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })\n")
+		fmt.Fprintf(&b, "    $env:%s = 'powershell'\n", COMPLETE_ENV)
+		b.WriteString("    try {\n")
+		b.WriteString("        $lines = & $words[0] @($words | Select-Object -Skip 1) \"$wordToComplete\" 2>$null\n")
+		b.WriteString("    } finally {\n")
+		fmt.Fprintf(&b, "        Remove-Item Env:%s\n", COMPLETE_ENV)
+		b.WriteString("    }\n")
+		b.WriteString("    $lines | ForEach-Object {\n")
+		b.WriteString("        $value, $description = $_ -split \"`t\", 2\n")
+		b.WriteString("        if (-not $description) { $description = $value }\n")
+		b.WriteString("        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)\n")
+		b.WriteString("    }\n")
+		b.WriteString("}\n")
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+	return b.String(), nil
+}
+
+// PrintDynamicCompletion prints the dynamic completion script for the given shell to file, or Stdout if file is nil.
+func (ap *ArgumentParser) PrintDynamicCompletion(shell string, file io.Writer) error {
+	script, err := ap.FormatDynamicCompletion(shell)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		file = ap.stdout()
+	}
+	ap.printMessage(script, file)
+	return nil
+}
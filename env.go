@@ -0,0 +1,38 @@
+package argparse
+
+import (
+	"strings"
+)
+
+// envName returns the environment variable providing the value of action:
+// its Env, or the upper-cased dest with envPrefix for optionals. An empty
+// name means that the action has no environment variable.
+func envName(action ActionInterface, envPrefix string) string {
+	act := action.Struct()
+	if act.Env == SUPPRESS {
+		return ""
+	}
+	if act.Env != "" {
+		return act.Env
+	}
+	if envPrefix == "" || len(act.OptionStrings) == 0 || act.Dest == SUPPRESS {
+		return ""
+	}
+	switch action.(type) {
+	case *HelpAction, *VersionAction, *SubParsersAction, *ConfigAction:
+		return ""
+	}
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(act.Dest, "-", "_"))
+}
+
+// EnvArgStrings_ converts the value of the environment variable of action to
+// the arg strings of each time the action is taken, the values of actions
+// accepting several values are split at LIST_SEPARATOR, or at their
+// Separator by GetValues.
+func (ap *ArgumentParser) EnvArgStrings_(action ActionInterface, value string) ([][]string, error) {
+	items := []string{value}
+	if action.Struct().Separator == "" && acceptsList(action) {
+		items = splitList(value)
+	}
+	return ap.SourceArgStrings_(action, items)
+}
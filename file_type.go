@@ -1 +1,95 @@
 package argparse
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileType is a factory for creating file object types.
+//
+// Instances of FileType are typically passed as Type to AddArgument, e.g.
+// Type: NewFileType("w", -1, "", ""). The argument string is converted to an
+// opened *os.File, with the special argument "-" meaning the Stdin of the
+// parser for read modes and its Stdout for write modes.
+type FileType struct {
+	Mode     string // The file mode, as in Python's open(), e.g. "r", "w", "a", "x", "rb", "r+"
+	Bufsize  int    // Kept for compatibility with Python's FileType, not used by Go
+	Encoding string // Kept for compatibility with Python's FileType, not used by Go
+	Errors   string // Kept for compatibility with Python's FileType, not used by Go
+}
+
+// NewFileType creates a new FileType, mode defaults to "r".
+func NewFileType(mode string, bufsize int, encoding string, errors string) *FileType {
+	if mode == "" {
+		mode = "r"
+	}
+	return &FileType{
+		Mode:     mode,
+		Bufsize:  bufsize,
+		Encoding: encoding,
+		Errors:   errors,
+	}
+}
+
+// Call opens the file named by argString using the FileType mode, "-" is
+// os.Stdin or os.Stdout.
+func (ft *FileType) Call(argString string) (any, error) {
+	return ft.open(argString, os.Stdin, os.Stdout)
+}
+
+// open opens the file named by argString, "-" is stdin or stdout.
+func (ft *FileType) open(argString string, stdin io.Reader, stdout io.Writer) (any, error) {
+	// the special argument "-" means stdin or stdout
+	if argString == "-" {
+		if strings.Contains(ft.Mode, "r") {
+			return stdin, nil
+		} else if strings.ContainsAny(ft.Mode, "wax") {
+			return stdout, nil
+		}
+		return nil, fmt.Errorf("argument \"-\" with mode %q", ft.Mode)
+	}
+
+	// all other arguments are used as file names
+	file, err := os.OpenFile(argString, ft.flag(), 0666)
+	if err != nil {
+		return nil, NewArgumentTypeError(fmt.Sprintf("can't open '%s': %v", argString, err))
+	}
+	return file, nil
+}
+
+// flag converts the Python-style mode to os.OpenFile flags.
+func (ft *FileType) flag() int {
+	var flag int
+	switch {
+	case strings.Contains(ft.Mode, "w"):
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	case strings.Contains(ft.Mode, "a"):
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	case strings.Contains(ft.Mode, "x"):
+		flag = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	default:
+		flag = os.O_RDONLY
+	}
+	if strings.Contains(ft.Mode, "+") {
+		flag &^= os.O_WRONLY
+		flag |= os.O_RDWR
+	}
+	return flag
+}
+
+// Repr returns a string representation of the FileType.
+func (ft *FileType) Repr() string {
+	args := []string{fmt.Sprintf("%q", ft.Mode)}
+	if ft.Bufsize != -1 && ft.Bufsize != 0 {
+		args = append(args, fmt.Sprintf("%d", ft.Bufsize))
+	}
+	if ft.Encoding != "" {
+		args = append(args, fmt.Sprintf("encoding=%q", ft.Encoding))
+	}
+	if ft.Errors != "" {
+		args = append(args, fmt.Sprintf("errors=%q", ft.Errors))
+	}
+	return fmt.Sprintf("FileType(%s)", strings.Join(args, ", "))
+}
@@ -0,0 +1,87 @@
+package argparse
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Handle is a typed handle on the value of an argument added with Add, its
+// value is set each time the parser parses arguments.
+type Handle[T any] struct {
+	action ActionInterface
+	value  T
+	found  bool
+}
+
+// Add adds argument to container, a parser or one of its groups, and
+// returns a handle on its value of type T. Unless set, the Type of the
+// argument converts the arg strings to T: int, float64, string, bool
+// (store_true for options), time.Duration and the types implementing
+// encoding.TextUnmarshaler, or their pointers for optional values. Slices
+// collect several values, with append for options and nargs "*" for
+// positionals.
+//
+//	port := argparse.Add[int](parser, &argparse.Argument{OptionStrings: []string{"--port"}})
+func Add[T any](container ActionsContainerInterface, argument *Argument) *Handle[T] {
+	parser := parserOf(container)
+	if parser == nil {
+		panic(fmt.Sprintf("cannot add a typed argument to %T, expected a parser or one of its groups", container))
+	}
+
+	prefixChars := container.Struct().PrefixChars
+	positional := len(argument.OptionStrings) == 1 && !isArgInChars(argument.OptionStrings[0][:1], prefixChars)
+	if _, err := inferArgument(argument, reflect.TypeFor[T](), positional); err != nil {
+		panic(fmt.Sprintf("argument %v: %v", argument.OptionStrings, err))
+	}
+
+	handle := &Handle[T]{action: container.AddArgument(argument)}
+	parser.valueSetters = append(parser.valueSetters, handle.set)
+	return handle
+}
+
+// Value returns the value of the argument after parsing, the zero value
+// of T before.
+func (h *Handle[T]) Value() T {
+	return h.value
+}
+
+// Found reports whether the namespace of the last parse had a value for
+// the argument, e.g. false if its default is SUPPRESS.
+func (h *Handle[T]) Found() bool {
+	return h.found
+}
+
+// Action returns the action of the argument.
+func (h *Handle[T]) Action() ActionInterface {
+	return h.action
+}
+
+// set sets the value of the handle from namespace.
+func (h *Handle[T]) set(namespace *Namespace) error {
+	var value T
+	h.value = value
+	dest := h.action.Struct().Dest
+	result, found := namespace.Get(dest)
+	h.found = found
+	if !found {
+		return nil
+	}
+	if err := decodeValue(result, reflect.ValueOf(&h.value).Elem(), reflect.TypeFor[T]().String()); err != nil {
+		err.Dest = dest
+		return err
+	}
+	return nil
+}
+
+// parserOf returns the parser container belongs to, or nil.
+func parserOf(container ActionsContainerInterface) *ArgumentParser {
+	switch c := container.(type) {
+	case *ArgumentParser:
+		return c
+	case *ArgumentGroup:
+		return parserOf(c.container.self())
+	case *MutuallyExclusiveGroup:
+		return parserOf(c.container)
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package argparse
 
 import (
 	"fmt"
-	"reflect"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Message building methods
@@ -13,30 +15,28 @@ type HelpFormatterMessagesInterface interface {
 	EndSection()                                                             // +
 	AddText(string)                                                          // +
 	AddUsage(string, []ActionInterface, []ActionsContainerInterface, string) // +
-	AddArgument(ActionInterface)                                             // ?
+	AddArgument(ActionInterface)                                             // +
 	AddArguments([]ActionInterface)                                          // +
 }
 
 // Help-formatting methods
 type HelpFormattingMethodsInterface interface {
-	FormatHelp() string               // +
-	formatHelpCallBack(...any) string // +
-	JoinParts_([]string) string       // +
+	FormatHelp() string         // +
+	JoinParts_([]string) string // +
 	FormatUsage_(string, []ActionInterface, []ActionsContainerInterface, string) string
 	FormatActionsUsage_([]ActionInterface, []ActionsContainerInterface) string     // +
-	GetActionsUsageParts_([]ActionInterface, []ActionsContainerInterface) []string // -
+	GetActionsUsageParts_([]ActionInterface, []ActionsContainerInterface) []string // +
 
 	FormatText_(string) string                      // +
-	FormatAction_(ActionInterface) string           // -
-	formatActionCallback_(...any) string            // +
+	FormatAction_(ActionInterface) string           // +
 	FormatActionInvocation_(ActionInterface) string // +
 
-	MetaVarFormatter_(ActionInterface, string) func(int) []string // -
-	FormatArgs_(ActionInterface, string) string                   // +
-	ExpandHelp_(ActionInterface, string) string                   // -
-	IterIndentedSubactions_(ActionInterface) []ActionInterface    // +
-	SplitLines_(string, int) []string                             // -
-	FillText_(string, int, string) string                         // ?
+	MetaVarFormatter_(ActionInterface, string) func(int) []string   // +
+	FormatArgs_(ActionInterface, string) string                     // +
+	ExpandHelp_(ActionInterface) string                             // +
+	IterIndentedSubactions_(ActionInterface, func(ActionInterface)) // +
+	SplitLines_(string, int) []string                               // +
+	FillText_(string, int, string) string                           // +
 
 	GetHelpString_(action ActionInterface) string                  // +
 	GetDefaultMetaVarForOptional_(action ActionInterface) string   // +
@@ -53,10 +53,18 @@ type HelpFormatterInterface interface {
 	HelpFormattingMethodsInterface
 }
 
+// NewHelpFormatterFunc is the type of the formatter constructors, passed as
+// the formatterClass keyword argument of NewArgumentParser.
+type NewHelpFormatterFunc = func(prog string, indentIncrement, maxHelpPosition, width int) HelpFormatterInterface
+
 const DefaultTerminalWidth = 80
 
+// GetTerminalWidth returns the terminal width from the COLUMNS environment
+// variable, or DefaultTerminalWidth.
 func GetTerminalWidth() int {
-	// FIXME: not done
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
 	return DefaultTerminalWidth
 }
 
@@ -107,7 +115,11 @@ func (s *Section_) FormatHelp(...any) string {
 }
 
 // HelpFormatter handles the generation of help messages and formatting.
+//
+// The formatters embedding *HelpFormatter override its methods, Formatter is
+// the outermost formatter, through which the overridable methods are called.
 type HelpFormatter struct {
+	Formatter         HelpFormatterInterface
 	Prog_             string
 	IndentIncrement   int
 	MaxHelpPosition   int
@@ -119,6 +131,8 @@ type HelpFormatter struct {
 	CurrentSection    *Section_
 	WhitespaceMatcher *regexp.Regexp
 	LongBreakMatcher  *regexp.Regexp
+	EnvPrefix         string        // The parser's prefix of the environment variable names
+	Constraints       []*Constraint // The parser's constraints, noted in the help of their arguments
 }
 
 func NewHelpFormatter(prog string, indentIncrement, maxHelpPosition, width int) HelpFormatterInterface {
@@ -149,6 +163,7 @@ func NewHelpFormatter(prog string, indentIncrement, maxHelpPosition, width int)
 
 	formatter.RootSection = rootSection
 	formatter.CurrentSection = formatter.RootSection
+	formatter.Formatter = formatter
 
 	return formatter
 }
@@ -157,6 +172,12 @@ func (hf *HelpFormatter) Struct() *HelpFormatter {
 	return hf
 }
 
+// setFormatter makes formatter the outermost formatter of hf.
+func (hf *HelpFormatter) setFormatter(formatter HelpFormatterInterface) {
+	hf.Formatter = formatter
+	hf.RootSection.Formatter = formatter
+}
+
 // Indent increases the current indentation level.
 func (hf *HelpFormatter) Indent_() {
 	hf.CurrentIndent_ += hf.IndentIncrement
@@ -172,14 +193,6 @@ func (hf *HelpFormatter) Dedent_() {
 	hf.Level--
 }
 
-// func (hf *HelpFormatter) AddItem_(func_ func(...any) string, args ...any) {
-// 	section := &SectionItem_{
-// 		Func: func_,
-// 		Args: args,
-// 	}
-// 	hf.CurrentSection.Items = append(hf.CurrentSection.Items, section)
-// }
-
 // AddItem_ adds a function and its arguments to the current section's items.
 func (hf *HelpFormatter) AddItem_(func_ func(...any) string, args ...any) {
 	// Ensure the CurrentSection is initialized
@@ -202,71 +215,57 @@ func (hf *HelpFormatter) AddItem_(func_ func(...any) string, args ...any) {
 // ========================
 
 func (hf *HelpFormatter) StartSection(heading string) {
-	hf.Indent_()
+	hf.Formatter.Indent_()
 	section := &Section_{
-		Formatter: hf,
+		Formatter: hf.Formatter,
 		Parent:    hf.CurrentSection,
 		Heading:   heading,
 	}
-	hf.AddItem_(section.FormatHelp, nil)
+	hf.Formatter.AddItem_(section.FormatHelp)
 	hf.CurrentSection = section
 }
 
 func (hf *HelpFormatter) EndSection() {
 	hf.CurrentSection = hf.CurrentSection.Parent
-	hf.Dedent_()
+	hf.Formatter.Dedent_()
 }
 
 func (hf *HelpFormatter) AddText(text string) {
 	if text != SUPPRESS && text != "" {
-		hf.AddItem_(hf.formatHelpCallBack, text)
+		hf.Formatter.AddItem_(hf.formatTextCallback_, text)
 	}
 }
 
 // AddUsage adds the usage information to the formatter
 func (hf *HelpFormatter) AddUsage(usage string, actions []ActionInterface, groups []ActionsContainerInterface, prefix string) {
 	if usage != SUPPRESS {
-		// Prepare arguments as a slice of any type
-		args := []any{usage, actions, groups, prefix}
-		// Add the formatted item to the current section
-		hf.AddItem_(hf.formatHelpCallBack, args...)
+		hf.Formatter.AddItem_(hf.formatUsageCallback_, usage, actions, groups, prefix)
 	}
 }
 
-// func (hf *HelpFormatter) AddUsage(usage string, actions []ActionInterface, groups []ActionsContainerInterface, prefix string) {
-// 	if usage != SUPPRESS {
-// 		args := []any{usage, actions, groups, prefix}
-// 		hf.AddItem_(hf.FormatHelp, args...)
-// 	}
-// }
-
 // AddArgument adds a formatted argument to the help formatter
 func (hf *HelpFormatter) AddArgument(action ActionInterface) {
 	if action.Struct().Help != SUPPRESS {
 
 		// find all invocations
-		getInvocation := hf.FormatActionInvocation_
-		invocationLengths := []int{len(getInvocation(action)) + hf.CurrentIndent_}
-		for _, subaction := range hf.IterIndentedSubactions_(action) {
-			invocationLengths = append(invocationLengths, len(getInvocation(subaction))+hf.CurrentIndent_)
-		}
+		getInvocation := hf.Formatter.FormatActionInvocation_
+		invocationLengths := []int{utf8.RuneCountInString(getInvocation(action)) + hf.CurrentIndent_}
+		hf.Formatter.IterIndentedSubactions_(action, func(subaction ActionInterface) {
+			invocationLengths = append(invocationLengths, utf8.RuneCountInString(getInvocation(subaction))+hf.CurrentIndent_)
+		})
 
 		// update the maximum item length
 		actionLength := max(invocationLengths...)
 		hf.ActionMaxLength = max(hf.ActionMaxLength, actionLength)
 
 		// add the item to the list
-		// FIXME: not done
-		hf.AddItem_(hf.formatActionCallback_, action)
+		hf.Formatter.AddItem_(hf.formatActionCallback_, action)
 	}
 }
 
 func (hf *HelpFormatter) AddArguments(actions []ActionInterface) {
-	if len(actions) == 0 {
-		return
-	}
 	for _, action := range actions {
-		hf.AddArgument(action)
+		hf.Formatter.AddArgument(action)
 	}
 }
 
@@ -274,10 +273,6 @@ func (hf *HelpFormatter) AddArguments(actions []ActionInterface) {
 // Help-formatting methods
 // =======================
 
-func (hp *HelpFormatter) formatHelpCallBack(...any) string {
-	return hp.FormatHelp()
-}
-
 func (hf *HelpFormatter) FormatHelp() string {
 	help := hf.RootSection.FormatHelp()
 	if help != "" {
@@ -300,14 +295,18 @@ func (hf *HelpFormatter) JoinParts_(partStrings []string) string {
 	return strings.Join(parts, "")
 }
 
+func (hf *HelpFormatter) formatUsageCallback_(args ...any) string {
+	return hf.Formatter.FormatUsage_(args[0].(string), args[1].([]ActionInterface), args[2].([]ActionsContainerInterface), args[3].(string))
+}
+
 func (hf *HelpFormatter) FormatUsage_(usage string, actions []ActionInterface, groups []ActionsContainerInterface, prefix string) string {
-	if prefix != "" {
+	if prefix == "" {
 		prefix = "usage: "
 	}
 
 	// if usage is specified, use that
 	if usage != "" {
-		usage = fmt.Sprintf(usage, hf.Prog_)
+		usage = formatKeys(usage, map[string]any{"prog": hf.Prog_})
 	} else if usage == "" && len(actions) == 0 {
 		// if no optionals or positionals are available, usage is just prog
 		usage = formatKeys("%(prog)s", map[string]any{"prog": hf.Prog_})
@@ -319,7 +318,7 @@ func (hf *HelpFormatter) FormatUsage_(usage string, actions []ActionInterface, g
 		positionals := []ActionInterface{}
 
 		for _, action := range actions {
-			if action.Struct().OptionStrings != nil {
+			if len(action.Struct().OptionStrings) > 0 {
 				optionals = append(optionals, action)
 			} else {
 				positionals = append(positionals, action)
@@ -327,8 +326,8 @@ func (hf *HelpFormatter) FormatUsage_(usage string, actions []ActionInterface, g
 		}
 
 		// build full usage string
-		format := hf.FormatActionsUsage_
-		actionUsage := format(append(optionals, positionals...), groups)
+		format := hf.Formatter.FormatActionsUsage_
+		actionUsage := format(append(append([]ActionInterface{}, optionals...), positionals...), groups)
 		sList := []string{}
 		if prog != "" {
 			sList = append(sList, prog)
@@ -342,8 +341,8 @@ func (hf *HelpFormatter) FormatUsage_(usage string, actions []ActionInterface, g
 		textWidth := hf.Width_ - hf.CurrentIndent_
 		if len(prefix)+len(usage) > textWidth {
 			// break usage into wrappable parts
-			optParts := hf.GetActionsUsageParts_(optionals, groups)
-			posParts := hf.GetActionsUsageParts_(positionals, groups)
+			optParts := hf.Formatter.GetActionsUsageParts_(optionals, groups)
+			posParts := hf.Formatter.GetActionsUsageParts_(positionals, groups)
 
 			// helper for wrapping lines
 			getLines := func(parts []string, indent string, prefix string) []string {
@@ -389,7 +388,7 @@ func (hf *HelpFormatter) FormatUsage_(usage string, actions []ActionInterface, g
 			} else {
 				// if prog is long, put it on its own line
 				indent := strings.Repeat(" ", len(prefix))
-				parts := append(optParts, posParts...)
+				parts := append(append([]string{}, optParts...), posParts...)
 				lines = getLines(parts, indent, "")
 				if len(lines) > 1 {
 					lines = []string{}
@@ -409,289 +408,405 @@ func (hf *HelpFormatter) FormatUsage_(usage string, actions []ActionInterface, g
 
 func (hf *HelpFormatter) FormatActionsUsage_(actions []ActionInterface, groups []ActionsContainerInterface) string {
 	// Get the parts for actions usage
-	usageParts := hf.GetActionsUsageParts_(actions, groups)
+	usageParts := hf.Formatter.GetActionsUsageParts_(actions, groups)
 
 	// Join the parts with a space separator and return
 	return strings.Join(usageParts, " ")
 }
 
 func (hf *HelpFormatter) GetActionsUsageParts_(actions []ActionInterface, groups []ActionsContainerInterface) []string {
-	// FIXME: Not done
-	return []string{}
+	// find group indices and identify actions in groups
+	groupActions := make(map[ActionInterface]bool)
+	type groupRange struct {
+		start, end int
+		required   bool
+	}
+	var inserts []groupRange
+	for _, groupInterface := range groups {
+		group, ok := groupInterface.(*MutuallyExclusiveGroup)
+		if !ok || len(group.GroupActions) == 0 {
+			continue
+		}
+		suppressed := true
+		for _, action := range group.GroupActions {
+			if action.Struct().Help != SUPPRESS {
+				suppressed = false
+			}
+		}
+		if suppressed {
+			continue
+		}
+		start := -1
+		for i, action := range actions {
+			if action == group.GroupActions[0] {
+				start = i
+				break
+			}
+		}
+		end := start + len(group.GroupActions)
+		if start < 0 || end > len(actions) {
+			continue
+		}
+		contiguous := true
+		for i, action := range group.GroupActions {
+			if actions[start+i] != action {
+				contiguous = false
+			}
+		}
+		if contiguous {
+			for _, action := range group.GroupActions {
+				groupActions[action] = true
+			}
+			inserts = append(inserts, groupRange{start, end, group.Required})
+		}
+	}
+
+	// collect all actions format strings, suppressed arguments are
+	// marked with nil
+	parts := make([]*string, len(actions))
+	for i, action := range actions {
+		act := action.Struct()
+		var part string
+
+		if act.Help == SUPPRESS {
+			// suppressed arguments are marked with nil
+			continue
+		} else if len(act.OptionStrings) == 0 {
+			// produce all arg strings
+			defaultMetaVar := hf.Formatter.GetDefaultMetaVarForPositional_(action)
+			part = hf.Formatter.FormatArgs_(action, defaultMetaVar)
+
+			// if it's in a group, strip the outer []
+			if groupActions[action] && len(part) > 1 && part[0] == '[' && part[len(part)-1] == ']' {
+				part = part[1 : len(part)-1]
+			}
+		} else {
+			// produce the first way to invoke the option in brackets
+			optionString := act.OptionStrings[0]
+
+			// if the Optional doesn't take a value, format is:
+			//    -s or --long
+			if act.Nargs == 0 {
+				part = action.FormatUsage()
+			} else {
+				// if the Optional takes a value, format is:
+				//    -s ARGS or --long ARGS
+				defaultMetaVar := hf.Formatter.GetDefaultMetaVarForOptional_(action)
+				argsString := hf.Formatter.FormatArgs_(action, defaultMetaVar)
+				part = fmt.Sprintf("%s %s", optionString, argsString)
+			}
+
+			// make it look optional if it's not required or in a group
+			if !act.Required && !groupActions[action] {
+				part = fmt.Sprintf("[%s]", part)
+			}
+		}
+
+		// add the action string to the list
+		parts[i] = &part
+	}
+
+	// group mutually exclusive actions, the later groups first
+	insertedSeparators := make(map[int]bool)
+	for i := len(inserts) - 1; i >= 0; i-- {
+		insert := inserts[i]
+		var groupParts []string
+		for _, part := range parts[insert.start:insert.end] {
+			if part != nil {
+				groupParts = append(groupParts, *part)
+			}
+		}
+		groupSize := len(groupParts)
+		if groupSize == 0 {
+			continue
+		}
+		open, close := "[", "]"
+		if insert.required {
+			open, close = "(", ")"
+			if groupSize == 1 {
+				open, close = "", ""
+			}
+		}
+		groupParts[0] = open + groupParts[0]
+		groupParts[groupSize-1] = groupParts[groupSize-1] + close
+		for j, part := range groupParts[:groupSize-1] {
+			// insert a separator if not already done in a nested group
+			index := insert.start + j
+			if !insertedSeparators[index] {
+				part += " |"
+				insertedSeparators[index] = true
+			}
+			parts[index] = &part
+		}
+		last := groupParts[groupSize-1]
+		parts[insert.start+groupSize-1] = &last
+		for j := insert.start + groupSize; j < insert.end; j++ {
+			parts[j] = nil
+		}
+	}
+
+	// return the usage parts
+	result := []string{}
+	for _, part := range parts {
+		if part != nil {
+			result = append(result, *part)
+		}
+	}
+	return result
+}
+
+func (hf *HelpFormatter) formatTextCallback_(args ...any) string {
+	return hf.Formatter.FormatText_(args[0].(string))
 }
 
 func (hf *HelpFormatter) FormatText_(text string) string {
 	// Replace placeholders like '%(prog)' with actual values
-	text = formatKeys(text, map[string]any{"prog": hf.Prog_})
+	if strings.Contains(text, "%(prog)") {
+		text = formatKeys(text, map[string]any{"prog": hf.Prog_})
+	}
 
 	// Calculate the width and the indentation
 	textWidth := max(hf.Width_-hf.CurrentIndent_, 11)
 	indent := strings.Repeat(" ", hf.CurrentIndent_)
 
-	// Format the text with wrapping
-	formattedText := hf.FillText_(text, textWidth, indent)
-
-	// Add line breaks at the end, if needed
-	return formattedText + "\n\n"
+	// Format the text with wrapping, and add line breaks at the end
+	return hf.Formatter.FillText_(text, textWidth, indent) + "\n\n"
 }
 
-// func (hf *HelpFormatter) FormatText_(text string) string {
-// 	text = formatKeys(text, map[string]any{"prog": hf.Prog_})
-// 	textWidth := max(hf.Width_-hf.CurrentIndent_, 11)
-// 	indent := strings.Repeat(" ", hf.CurrentIndent_)
-// 	return hf.FillText_(text, textWidth, indent)
-// }
-
 func (hf *HelpFormatter) formatActionCallback_(args ...any) string {
 	if intf, ok := args[0].(ActionInterface); ok {
 		// Use intf instead of action to call FormatAction_
-		return hf.FormatAction_(intf)
+		return hf.Formatter.FormatAction_(intf)
 	}
 	// Optionally, handle the error gracefully (e.g., panic with a message).
 	panic("Invalid type for FormatAction_ callback.")
 }
 
 func (hf *HelpFormatter) FormatAction_(action ActionInterface) string {
-	// FIXME: Not done
+	act := action.Struct()
 
-	// act := action.(ActionInterface)
+	// determine the required width and the entry label
+	helpPosition := min(hf.ActionMaxLength+2, hf.MaxHelpPosition)
+	helpWidth := max(hf.Width_-helpPosition, 11)
+	actionWidth := helpPosition - hf.CurrentIndent_ - 2
+	actionHeader := hf.Formatter.FormatActionInvocation_(action)
 
-	return ""
-}
+	// the help text, followed by the environment variable name, if any
+	var helpText string
+	if strings.TrimSpace(act.Help) != "" {
+		helpText = hf.Formatter.ExpandHelp_(action)
+	}
+	env := envName(action, hf.EnvPrefix)
+	if env != "" {
+		helpText = strings.TrimSpace(fmt.Sprintf("%s [env var: %s]", helpText, env))
+	}
+	for _, constraint := range hf.Constraints {
+		if note := constraint.Note(action); note != "" {
+			helpText = strings.TrimSpace(fmt.Sprintf("%s [%s]", helpText, note))
+		}
+	}
 
-func (hf *HelpFormatter) FormatActionInvocation_(action ActionInterface) string {
-	// If option strings are nil, it's a positional argument
-	if action.Struct().OptionStrings == nil {
-		defaultValue := hf.GetDefaultMetaVarForPositional_(action)
-		return strings.Join(hf.MetaVarFormatter_(action, defaultValue)(1), " ")
+	var indentFirst int
+	if helpText == "" {
+		// no help; start on same line and add a final newline
+		actionHeader = fmt.Sprintf("%*s%s\n", hf.CurrentIndent_, "", actionHeader)
+	} else if utf8.RuneCountInString(actionHeader) <= actionWidth {
+		// short action name; start on the same line and pad two spaces
+		actionHeader = fmt.Sprintf("%*s%-*s  ", hf.CurrentIndent_, "", actionWidth, actionHeader)
+		indentFirst = 0
+	} else {
+		// long action name; start on the next line
+		actionHeader = fmt.Sprintf("%*s%s\n", hf.CurrentIndent_, "", actionHeader)
+		indentFirst = helpPosition
 	}
 
-	// Handle option arguments with a value (e.g., -s, --long ARGS)
-	nargs, isInt := action.Struct().Nargs.(int)
+	// collect the pieces of the action help
+	parts := []string{actionHeader}
 
-	switch {
-	case !isInt: // Handle if nargs is not an int (e.g., string or other cases)
-		// Fallback or error handling for unsupported nargs type
-		return "Unsupported nargs type"
-	case nargs == 0:
-		// If nargs is 0, we return only option strings
-		return strings.Join(action.Struct().OptionStrings, ", ")
+	// if there was help for the action, add lines of help text
+	if helpText != "" {
+		helpLines := hf.Formatter.SplitLines_(helpText, helpWidth)
+		if len(helpLines) > 0 {
+			parts = append(parts, fmt.Sprintf("%*s%s\n", indentFirst, "", helpLines[0]))
+			for _, line := range helpLines[1:] {
+				parts = append(parts, fmt.Sprintf("%*s%s\n", helpPosition, "", line))
+			}
+		}
+	} else if !strings.HasSuffix(actionHeader, "\n") {
+		// or add a newline if the description doesn't end with one
+		parts = append(parts, "\n")
+	}
 
-	case nargs > 0:
-		// If nargs is greater than 0, format args as "option ARGS"
-		defaultValue := hf.GetDefaultMetaVarForOptional_(action)
-		argsString := hf.FormatArgs_(action, defaultValue)
-		return strings.Join(action.Struct().OptionStrings, ", ") + " " + argsString
+	// if there are any sub-actions, add their help as well
+	hf.Formatter.IterIndentedSubactions_(action, func(subaction ActionInterface) {
+		parts = append(parts, hf.Formatter.FormatAction_(subaction))
+	})
 
-	default:
-		// Handle unsupported nargs value (e.g., panic handling)
-		return "Invalid nargs value"
-	}
-}
-
-// func (hf *HelpFormatter) FormatActionInvocation_(action ActionInterface) string {
-// 	if action.Struct().OptionStrings == nil {
-// 		defaultValue := hf.GetDefaultMetaVarForPositional_(action)
-// 		return strings.Join(hf.MetaVarFormatter_(action, defaultValue)(1), " ")
-// 	} else {
-// 		// if the Optional doesn't take a value, format is:
-// 		//     -s, --long
-// 		if nargs, ok := action.Struct().Nargs.(int); ok && nargs == 0 {
-// 			return strings.Join(action.Struct().OptionStrings, ", ")
-// 		} else {
-// 			// if the Optional takes a value, format is:
-// 			//    -s, --long ARGS
-// 			defaultValue := hf.GetDefaultMetaVarForOptional_(action)
-// 			argsString := hf.FormatArgs_(action, defaultValue)
-// 			return strings.Join(action.Struct().OptionStrings, ", ") + " " + argsString
-// 		}
-// 	}
-// }
+	// return a single string
+	return hf.Formatter.JoinParts_(parts)
+}
 
-func (hf *HelpFormatter) MetaVarFormatter_(action ActionInterface, defaultMetaVar string) func(int) []string {
-	// FIXME: Not done
-	// return nil
-	var result string
-	if action.Struct().MetaVar != "" {
-		result = action.Struct().MetaVar.(string)
-	} else if action.Struct().Choices != nil {
-		var strChoices []string
-		for _, choice := range action.Struct().Choices {
-			strChoices = append(strChoices, fmt.Sprintf("%s", choice))
-		}
-		result = strings.Join(strChoices, ",")
-	} else {
-		result = defaultMetaVar
+func (hf *HelpFormatter) FormatActionInvocation_(action ActionInterface) string {
+	act := action.Struct()
+
+	// positionals show their metavar, e.g. FILE
+	if len(act.OptionStrings) == 0 {
+		defaultValue := hf.Formatter.GetDefaultMetaVarForPositional_(action)
+		return strings.Join(hf.Formatter.MetaVarFormatter_(action, defaultValue)(1), " ")
 	}
 
-	// format := func(tupleSize int) interface{} {
-	// 	// Check if result is already a tuple (slice in Go)
+	// if the Optional doesn't take a value, format is:
+	//    -s, --long
+	if act.Nargs == 0 {
+		return strings.Join(act.OptionStrings, ", ")
+	}
 
-	// 	result
-	// }
-	// return format
+	// if the Optional takes a value, format is:
+	//    -s, --long ARGS
+	defaultValue := hf.Formatter.GetDefaultMetaVarForOptional_(action)
+	argsString := hf.Formatter.FormatArgs_(action, defaultValue)
+	return strings.Join(act.OptionStrings, ", ") + " " + argsString
+}
+
+// MetaVarFormatter_ returns a function returning the metavar repeated
+// tupleSize times, or the metavar tuple if MetaVar is a []string.
+func (hf *HelpFormatter) MetaVarFormatter_(action ActionInterface, defaultMetaVar string) func(int) []string {
+	act := action.Struct()
+	var result string
+	switch metavar := act.MetaVar.(type) {
+	case []string:
+		return func(tupleSize int) []string {
+			return metavar
+		}
+	case string:
+		result = metavar
+	default:
+		if act.Choices != nil {
+			choices := make([]string, len(act.Choices))
+			for i, choice := range act.Choices {
+				choices[i] = fmt.Sprintf("%v", choice)
+			}
+			result = fmt.Sprintf("{%s}", strings.Join(choices, ","))
+		} else {
+			result = defaultMetaVar
+		}
+	}
 
-	format := func(tupleSize int) []string {
-		return []string{result}
+	return func(tupleSize int) []string {
+		metavars := make([]string, tupleSize)
+		for i := range metavars {
+			metavars[i] = result
+		}
+		return metavars
 	}
-	return format
 }
 
 func (hf *HelpFormatter) FormatArgs_(action ActionInterface, defaultMetaVar string) string {
-	getMetaVar := hf.MetaVarFormatter_(action, defaultMetaVar)
+	getMetaVar := hf.Formatter.MetaVarFormatter_(action, defaultMetaVar)
 
-	act := action.Struct()
-	var result string
+	// metavar returns the metavars for nargs, checking the tuple length
+	metavar := func(tupleSize int) []string {
+		metavars := getMetaVar(tupleSize)
+		if len(metavars) != tupleSize {
+			panic("length of metavar tuple does not match nargs")
+		}
+		return metavars
+	}
 
+	act := action.Struct()
 	switch nargs := act.Nargs.(type) {
 	case nil:
-		result = fmt.Sprintf("%s", getMetaVar(1))
+		return metavar(1)[0]
 	case string:
 		switch nargs {
 		case OPTIONAL:
-			result = fmt.Sprintf("[%s]", getMetaVar(1))
+			return fmt.Sprintf("[%s]", metavar(1)[0])
 		case ZERO_OR_MORE:
-			metavar := getMetaVar(1)
-			if len(metavar) == 2 {
-				result = fmt.Sprintf("[%s [%s ...]]", metavar, metavar)
-			} else {
-				result = fmt.Sprintf("[%s ...]", metavar)
+			if metavars := getMetaVar(1); len(metavars) == 2 {
+				return fmt.Sprintf("[%s [%s ...]]", metavars[0], metavars[1])
 			}
+			return fmt.Sprintf("[%s ...]", metavar(1)[0])
 		case ONE_OR_MORE:
-			result = fmt.Sprintf("[%s ...]", getMetaVar(2))
+			metavars := metavar(2)
+			return fmt.Sprintf("%s [%s ...]", metavars[0], metavars[1])
 		case REMAINDER:
-			result = "..."
+			return "..."
 		case PARSER:
-			result = fmt.Sprintf("%s ...", getMetaVar(1))
+			return fmt.Sprintf("%s ...", metavar(1)[0])
 		case SUPPRESS:
-			result = ""
-		default:
-			panic("invalid nargs value")
+			return ""
 		}
 	case int:
-		formats := strings.Repeat("%s ", nargs)
-		result = fmt.Sprintf(formats, getMetaVar(nargs))
-	default:
-		panic("invalid nargs value")
+		return strings.Join(metavar(nargs), " ")
 	}
-
-	return result
+	panic("invalid nargs value")
 }
 
-func (hf *HelpFormatter) ExpandHelp_(action ActionInterface, defaultMetaVar string) string {
-	helpString := hf.GetHelpString_(action)
-	if !strings.Contains(helpString, "%") {
-		return helpString
+// ExpandHelp_ substitutes the %(name)s specifiers of the help string, e.g.
+// %(default)s, %(type)s, %(choices)s or %(prog)s.
+func (hf *HelpFormatter) ExpandHelp_(action ActionInterface) string {
+	act := action.Struct()
+	params := map[string]any{
+		"option_strings": act.OptionStrings,
+		"dest":           act.Dest,
+		"nargs":          act.Nargs,
+		"const":          act.Const,
+		"default":        act.Default,
+		"type":           act.Type,
+		"choices":        act.Choices,
+		"required":       act.Required,
+		"help":           act.Help,
+		"metavar":        act.MetaVar,
+		"deprecated":     act.Deprecated,
+		"prog":           hf.Prog_,
+	}
+	for name, value := range params {
+		if value == SUPPRESS {
+			delete(params, name)
+		}
+	}
+	if act.Type != nil {
+		params["type"] = typeName(act.Type)
+	}
+	if _, found := params["default"]; found && act.Sensitive && act.Default != nil {
+		params["default"] = SENSITIVE_MASK
 	}
-	// params := map[string]interface{}{
-	// 	"prog": hf.Prog_,
-	// }
-	// for key, value := range action.Extra {
-	// 	params[key] = value
-	// }
-	// FIXME: Not done
-	return ""
+	if act.Choices != nil {
+		choices := make([]string, len(act.Choices))
+		for i, choice := range act.Choices {
+			choices[i] = fmt.Sprintf("%v", choice)
+		}
+		params["choices"] = strings.Join(choices, ", ")
+	}
+	return formatKeys(hf.Formatter.GetHelpString_(action), params)
 }
 
-func (hf *HelpFormatter) IterIndentedSubactions_(action ActionInterface) []ActionInterface {
-	// Get subactions from the action
-	getSubActions := action.GetSubActions_
-
-	// Get the subactions
-	subactions := getSubActions()
-
-	// If subactions are not nil, indent and return them
-	if subactions != nil {
-		hf.Indent_() // Add indentation before processing subactions
-		// Simply return the subactions here, no need to append them to themselves
-		hf.Dedent_() // Remove indentation after processing subactions
+// IterIndentedSubactions_ calls yield for each of the subactions of action,
+// with the indentation increased.
+func (hf *HelpFormatter) IterIndentedSubactions_(action ActionInterface, yield func(ActionInterface)) {
+	subactions := action.GetSubActions_()
+	if subactions == nil {
+		return
 	}
-
-	return subactions
+	hf.Formatter.Indent_()
+	for _, subaction := range subactions {
+		yield(subaction)
+	}
+	hf.Formatter.Dedent_()
 }
 
-// func (hf *HelpFormatter) IterIndentedSubactions_(action ActionInterface) []ActionInterface {
-// 	getSubActions := action.GetSubActions
-// 	subactions := getSubActions()
-// 	if subactions != nil {
-// 		hf.Indent_()
-// 		subactions = append(subactions, subactions...)
-// 		hf.Dedent_()
-// 	}
-// 	return subactions
-// }
-
 func (hf *HelpFormatter) SplitLines_(text string, width int) []string {
-	// FIXME: Not done
-	// if width <= 0 {
-	// 	panic("wrapText: width must be greater than 0")
-	// }
-
-	var lines []string
-	// var line strings.Builder
-	// currentWidth := 0
-
-	// words := strings.Fields(text) // Split text into words
-	// for _, word := range words {
-	// 	wordLen := utf8.RuneCountInString(word)
-
-	// 	// If adding the word exceeds the width, finalize the current line
-	// 	if currentWidth+wordLen+1 > width && currentWidth > 0 {
-	// 		lines = append(lines, line.String())
-	// 		line.Reset()
-	// 		currentWidth = 0
-	// 	}
-
-	// 	// Add the word to the line
-	// 	if currentWidth > 0 {
-	// 		line.WriteString(" ")
-	// 		currentWidth++
-	// 	}
-	// 	line.WriteString(word)
-	// 	currentWidth += wordLen
-	// }
-
-	// // Add the last line if there's remaining text
-	// if line.Len() > 0 {
-	// 	lines = append(lines, line.String())
-	// }
-
-	return lines
+	text = strings.TrimSpace(hf.WhitespaceMatcher.ReplaceAllString(text, " "))
+	return wrapText(text, width)
 }
 
 func (hf *HelpFormatter) FillText_(text string, width int, indent string) string {
-	// NOTE: 'indent' omited
-
-	textWrap := func(text string, width int) string {
-		if width <= 0 {
-			return text
-		}
-
-		var result strings.Builder
-		var line strings.Builder
-
-		words := strings.Fields(text)
-
-		for _, word := range words {
-			// Check if adding the word exceeds the width
-			if line.Len()+len(word)+1 > width { // +1 accounts for the space
-				// Append the current line to the result
-				result.WriteString(strings.TrimSpace(line.String()) + "\n")
-				line.Reset()
-			}
-			// Append the word to the current line
-			line.WriteString(word + " ")
-		}
-
-		// Add any remaining text in the line to the result
-		if line.Len() > 0 {
-			result.WriteString(strings.TrimSpace(line.String()))
-		}
-
-		return result.String()
+	text = strings.TrimSpace(hf.WhitespaceMatcher.ReplaceAllString(text, " "))
+	lines := wrapText(text, width-utf8.RuneCountInString(indent))
+	for i, line := range lines {
+		lines[i] = indent + line
 	}
-
-	return textWrap(text, width)
+	return strings.Join(lines, "\n")
 }
 
 func (hf *HelpFormatter) GetHelpString_(action ActionInterface) string {
@@ -706,6 +821,52 @@ func (hf *HelpFormatter) GetDefaultMetaVarForPositional_(action ActionInterface)
 	return action.Struct().Dest
 }
 
+// wrapText wraps the words of text into lines of at most width characters,
+// words longer than width are broken, as Python's textwrap.wrap does.
+func wrapText(text string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+	var line []string
+	lineLen := 0
+	for _, word := range strings.Fields(text) {
+		wordLen := utf8.RuneCountInString(word)
+
+		// finish the line if the word doesn't fit, a word longer than a
+		// line fills the rest of it first
+		if len(line) > 0 && lineLen+1+wordLen > width {
+			if spaceLeft := width - lineLen - 1; wordLen > width && spaceLeft > 0 {
+				runes := []rune(word)
+				line = append(line, string(runes[:spaceLeft]))
+				word = string(runes[spaceLeft:])
+				wordLen -= spaceLeft
+			}
+			lines = append(lines, strings.Join(line, " "))
+			line, lineLen = nil, 0
+		}
+
+		// break the words longer than a line
+		for wordLen > width {
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+			wordLen -= width
+		}
+		if wordLen == 0 {
+			continue
+		}
+
+		if len(line) > 0 {
+			lineLen++
+		}
+		line = append(line, word)
+		lineLen += wordLen
+	}
+	if len(line) > 0 {
+		lines = append(lines, strings.Join(line, " "))
+	}
+	return lines
+}
+
 // min returns the smaller of two integers
 func min(nums ...int) int {
 	if len(nums) == 0 {
@@ -736,10 +897,17 @@ func max(nums ...int) int {
 	return maxVal
 }
 
+// RawDescriptionHelpFormatter retains any formatting in descriptions.
 type RawDescriptionHelpFormatter struct {
 	*HelpFormatter
 }
 
+func NewRawDescriptionHelpFormatter(prog string, indentIncrement, maxHelpPosition, width int) HelpFormatterInterface {
+	formatter := &RawDescriptionHelpFormatter{NewHelpFormatter(prog, indentIncrement, maxHelpPosition, width).(*HelpFormatter)}
+	formatter.setFormatter(formatter)
+	return formatter
+}
+
 func (fh *RawDescriptionHelpFormatter) FillText_(text string, width int, indent string) string {
 	var builder strings.Builder
 
@@ -754,105 +922,86 @@ func (fh *RawDescriptionHelpFormatter) FillText_(text string, width int, indent
 	return builder.String()
 }
 
+// RawTextHelpFormatter retains formatting of all help text.
 type RawTextHelpFormatter struct {
-	*HelpFormatter
+	*RawDescriptionHelpFormatter
 }
 
-func (fh *RawTextHelpFormatter) FillText_(text string, width int, indent string) string {
-	var builder strings.Builder
-
-	// Normalize line endings for consistent processing
-	lines := strings.SplitAfter(text, "\n") // Split while retaining line breaks
-
-	for _, line := range lines {
-		builder.WriteString(indent) // Add the indent
-		builder.WriteString(line)   // Add the original line (with line break if present)
-	}
-
-	return builder.String()
+func NewRawTextHelpFormatter(prog string, indentIncrement, maxHelpPosition, width int) HelpFormatterInterface {
+	formatter := &RawTextHelpFormatter{NewRawDescriptionHelpFormatter(prog, indentIncrement, maxHelpPosition, width).(*RawDescriptionHelpFormatter)}
+	formatter.setFormatter(formatter)
+	return formatter
 }
 
 func (fh *RawTextHelpFormatter) SplitLines_(text string, width int) []string {
-	return strings.SplitAfter(text, "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
 }
 
+// ArgumentDefaultsHelpFormatter adds default values to argument help.
 type ArgumentDefaultsHelpFormatter struct {
 	*HelpFormatter
 }
 
-func (fh *ArgumentDefaultsHelpFormatter) GetHelpString_(action ActionInterface) string {
-	// Extract the action structure for easier reference
-
-	containsHelper := func(slice []string, value string) bool {
-		for _, v := range slice {
-			if v == value {
-				return true
-			}
-		}
-		return false
-	}
-
-	actionStruct := action.Struct()
-
-	// Initialize help text
-	help := actionStruct.Help
-	if help == "" {
-		help = ""
-	}
-
-	// Check if help already contains %(default)
-	if !strings.Contains(help, "%(default)") {
-		// Add default value if applicable
-		if actionStruct.Default != SUPPRESS {
-			defaultingNargs := []string{OPTIONAL, ZERO_OR_MORE}
+func NewArgumentDefaultsHelpFormatter(prog string, indentIncrement, maxHelpPosition, width int) HelpFormatterInterface {
+	formatter := &ArgumentDefaultsHelpFormatter{NewHelpFormatter(prog, indentIncrement, maxHelpPosition, width).(*HelpFormatter)}
+	formatter.setFormatter(formatter)
+	return formatter
+}
 
-			// Handle Nargs as either string or number
-			switch t := actionStruct.Nargs.(type) {
-			case string:
-				// If Nargs is a string, check if it matches defaultingNargs
-				if len(actionStruct.OptionStrings) > 0 || containsHelper(defaultingNargs, t) {
-					help += fmt.Sprintf(" (default: %s)", actionStruct.Default)
-				}
-			case int:
-				// If Nargs is a number, no need to compare with defaultingNargs
-				if len(actionStruct.OptionStrings) > 0 {
-					help += fmt.Sprintf(" (default: %s)", actionStruct.Default)
-				}
-			default:
-				// Handle unexpected types gracefully (optional)
-				// help += fmt.Sprintf(" (default: %s)", actionStruct.Default)
-			}
+func (fh *ArgumentDefaultsHelpFormatter) GetHelpString_(action ActionInterface) string {
+	act := action.Struct()
+	help := act.Help
+	if !strings.Contains(help, "%(default)") && act.Default != SUPPRESS {
+		if len(act.OptionStrings) > 0 || act.Nargs == OPTIONAL || act.Nargs == ZERO_OR_MORE {
+			help += " (default: %(default)s)"
 		}
 	}
-
 	return help
 }
 
+// MetaVarTypeHelpFormatter uses the name of the Type as the default metavar.
 type MetaVarTypeHelpFormatter struct {
 	*HelpFormatter
 }
 
+func NewMetaVarTypeHelpFormatter(prog string, indentIncrement, maxHelpPosition, width int) HelpFormatterInterface {
+	formatter := &MetaVarTypeHelpFormatter{NewHelpFormatter(prog, indentIncrement, maxHelpPosition, width).(*HelpFormatter)}
+	formatter.setFormatter(formatter)
+	return formatter
+}
+
 func (fh *MetaVarTypeHelpFormatter) GetDefaultMetaVarForOptional_(action ActionInterface) string {
-	return reflect.TypeOf(action.Struct().Type).String()
+	return typeName(action.Struct().Type)
 }
 
 func (fh *MetaVarTypeHelpFormatter) GetDefaultMetaVarForPositional_(action ActionInterface) string {
-	return reflect.TypeOf(action.Struct().Type).String()
+	return typeName(action.Struct().Type)
 }
 
+// formatKeysMatcher matches the Python-style %(key)s specifiers and %%.
+var formatKeysMatcher = regexp.MustCompile(`%\((\w+)\)[sdr]|%%`)
+
+// formatKeys substitutes the %(key)s specifiers of str by values, like
+// Python's str % dict, specifiers of missing keys are left unchanged.
 func formatKeys(str string, values map[string]any) string {
-	// Create a regex pattern to match placeholders like %(key) or %(key)s
-	re := regexp.MustCompile(`%\((\w+)\)(s?)`)
-	// Iterate over matches
-	return re.ReplaceAllStringFunc(str, func(match string) string {
-		// Extract the key from the match (exclude the surrounding '%(' and ')')
-		key := match[2 : len(match)-2]
-		// Check if the key exists in the values map
+	return formatKeysMatcher.ReplaceAllStringFunc(str, func(match string) string {
+		if match == "%%" {
+			return "%"
+		}
+		key := match[2:strings.Index(match, ")")]
 		if value, exists := values[key]; exists {
-			// Replace with the formatted value (using %v to convert to string)
-			return fmt.Sprintf("%v", value)
+			return formatValue(value)
 		}
-		// If the key is not found, return the match unchanged
 		return match
 	})
 }
+
+// formatValue formats a value for help messages, nil and the booleans are
+// shown as None, True and False as in the Repr of a Namespace.
+func formatValue(value any) string {
+	switch value.(type) {
+	case nil, bool:
+		return pyRepr(value)
+	}
+	return fmt.Sprintf("%v", value)
+}
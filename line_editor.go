@@ -0,0 +1,123 @@
+package argparse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// lineEditor reads the lines of a REPL from a terminal, with the history
+// on the up and down keys and the completion of the parser on tab.
+type lineEditor struct {
+	in       *os.File
+	out      io.Writer
+	complete func(line string) (string, []Candidate)
+	reader   *bufio.Reader
+}
+
+// newLineEditor creates a new lineEditor reading from the terminal in,
+// completing the lines with complete.
+func newLineEditor(in *os.File, out io.Writer, complete func(line string) (string, []Candidate)) *lineEditor {
+	return &lineEditor{in: in, out: out, complete: complete, reader: bufio.NewReader(in)}
+}
+
+// ReadLine shows prompt and reads a line, io.EOF is returned for ctrl-D on
+// an empty line. The line is read without editing if the terminal can't be
+// put in raw mode.
+func (e *lineEditor) ReadLine(prompt string, history []string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	restore, err := makeRaw(e.in)
+	if err != nil {
+		line, err := e.reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	defer restore()
+
+	var line []rune
+	index := len(history) // the history entry shown, len(history) for the new line
+	draft := ""
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r\x1b[K%s%s", prompt, string(line))
+	}
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case 4: // ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		case 3: // ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			line = line[:0]
+			index = len(history)
+			redraw()
+		case 127, 8: // backspace
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case 21: // ctrl-U
+			line = line[:0]
+			redraw()
+		case '\t':
+			completed, candidates := e.complete(string(line))
+			line = []rune(completed)
+			e.listCandidates(candidates)
+			redraw()
+		case 27: // escape sequences, the up and down keys browse the history
+			next, _, _ := e.reader.ReadRune()
+			if next != '[' && next != 'O' {
+				continue
+			}
+			key, _, _ := e.reader.ReadRune()
+			switch {
+			case key == 'A' && index > 0:
+				if index == len(history) {
+					draft = string(line)
+				}
+				index--
+				line = []rune(history[index])
+			case key == 'B' && index < len(history):
+				index++
+				if index == len(history) {
+					line = []rune(draft)
+				} else {
+					line = []rune(history[index])
+				}
+			}
+			redraw()
+		default:
+			if r >= ' ' {
+				line = append(line, r)
+				fmt.Fprint(e.out, string(r))
+			}
+		}
+	}
+}
+
+// listCandidates lists the candidates below the line.
+func (e *lineEditor) listCandidates(candidates []Candidate) {
+	if len(candidates) == 0 {
+		return
+	}
+	fmt.Fprint(e.out, "\r\n")
+	for _, candidate := range candidates {
+		if candidate.Description != "" {
+			fmt.Fprintf(e.out, "%-24s %s\r\n", candidate.Value, candidate.Description)
+		} else {
+			fmt.Fprintf(e.out, "%s\r\n", candidate.Value)
+		}
+	}
+}
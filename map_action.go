@@ -0,0 +1,170 @@
+package argparse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// How map actions handle a key given several times.
+const (
+	DUPLICATE_KEYS_LAST_WINS = "last-wins"
+	DUPLICATE_KEYS_ERROR     = "error"
+)
+
+// MapAction stores KEY=VALUE arguments in a map[string]any, e.g.
+// --label env=prod --label team=core. The Type, Choices and Validate of the
+// argument apply to the values.
+type MapAction struct {
+	*Action
+
+	KeySeparator  string // The separator of the keys and values, "=" by default
+	DuplicateKeys string // One of the DUPLICATE_KEYS_ constants
+	values        *Action
+}
+
+// NewMapAction creates a new MapAction, taking one KEY=VALUE per occurrence
+// unless nargs is given.
+func NewMapAction(argument *Argument) ActionInterface {
+	switch v := argument.Nargs.(type) {
+	case int:
+		if v == 0 {
+			panic("nargs for map actions must be != 0")
+		}
+	case string, nil:
+	default:
+		panic(fmt.Sprintf("nargs must be an integer or a string literal (e.g., %s)", OPTIONAL))
+	}
+
+	separator := argument.KeySeparator
+	if separator == "" {
+		separator = "="
+	}
+	duplicateKeys := argument.DuplicateKeys
+	switch duplicateKeys {
+	case "":
+		duplicateKeys = DUPLICATE_KEYS_LAST_WINS
+	case DUPLICATE_KEYS_LAST_WINS, DUPLICATE_KEYS_ERROR:
+	default:
+		panic(fmt.Sprintf("invalid duplicate keys policy: '%s'", duplicateKeys))
+	}
+	metavar := argument.MetaVar
+	if metavar == nil {
+		metavar = "KEY" + separator + "VALUE"
+	}
+
+	action := &MapAction{
+		Action: &Action{
+			OptionStrings: argument.OptionStrings,
+			Dest:          argument.Dest,
+			Nargs:         argument.Nargs,
+			Const:         argument.Const,
+			Default:       argument.Default,
+			Required:      argument.Required,
+			Help:          argument.Help,
+			MetaVar:       metavar,
+			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
+			Prompt:        argument.Prompt,
+			PromptHidden:  argument.PromptHidden,
+			Sensitive:     argument.Sensitive,
+			Completer:     argument.Completer,
+		},
+		KeySeparator:  separator,
+		DuplicateKeys: duplicateKeys,
+	}
+
+	// the values are converted and checked as the arguments of an action
+	// with the same name
+	action.values = &Action{
+		OptionStrings: argument.OptionStrings,
+		Dest:          argument.Dest,
+		Type:          argument.Type,
+		Choices:       argument.Choices,
+		MetaVar:       metavar,
+		Validate:      argument.Validate,
+		Sensitive:     argument.Sensitive,
+	}
+	return action
+}
+
+// Call adds the KEY=VALUE values to the map in the namespace. A key given
+// several times is an error with DUPLICATE_KEYS_ERROR, keys of the default
+// can be overridden.
+func (a *MapAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	items := make(map[string]any)
+	if current, found := namespace.Get(a.Dest); found {
+		if currentMap, ok := current.(map[string]any); ok {
+			for key, value := range currentMap {
+				items[key] = value
+			}
+		}
+	}
+	defaults, _ := a.Default.(map[string]any)
+
+	var argStrings []string
+	switch v := values.(type) {
+	case nil:
+	case string:
+		argStrings = []string{v}
+	case []any:
+		for _, value := range v {
+			argStrings = append(argStrings, fmt.Sprintf("%v", value))
+		}
+	default:
+		return NewArgumentError(a.Action, fmt.Sprintf("expected %s values, got %T", a.values.MetaVar, values))
+	}
+
+	for _, argString := range argStrings {
+		key, valueString, found := strings.Cut(argString, a.KeySeparator)
+		if !found || key == "" {
+			return NewArgumentError(a.Action, fmt.Sprintf("expected %s, got '%s'", a.values.MetaVar, argString))
+		}
+		if previous, duplicate := items[key]; duplicate && a.DuplicateKeys == DUPLICATE_KEYS_ERROR {
+			if defaultValue, isDefault := defaults[key]; !isDefault || !reflect.DeepEqual(previous, defaultValue) {
+				return NewArgumentError(a.Action, fmt.Sprintf("duplicate key: '%s'", key))
+			}
+		}
+
+		value, err := parser.GetValue(a.values, valueString)
+		if err != nil {
+			return err
+		}
+		if err := parser.CheckValue(a.values, value); err != nil {
+			return err
+		}
+		if err := validateValues(a.values, value); err != nil {
+			return err
+		}
+		items[key] = value
+	}
+	namespace.Set(a.Dest, items)
+	return nil
+}
+
+// ExtendMapAction is a MapAction taking one or more KEY=VALUE arguments per
+// occurrence, e.g. --set a=1 b=2.
+type ExtendMapAction struct {
+	*MapAction
+}
+
+// NewExtendMapAction creates a new ExtendMapAction, nargs is "+" by default.
+func NewExtendMapAction(argument *Argument) ActionInterface {
+	if argument.Nargs == nil {
+		copied := *argument
+		copied.Nargs = ONE_OR_MORE
+		argument = &copied
+	}
+	return &ExtendMapAction{MapAction: NewMapAction(argument).(*MapAction)}
+}
+
+// mapActionOf returns the MapAction of a map or extend_map action, or nil.
+func mapActionOf(action ActionInterface) *MapAction {
+	switch a := action.(type) {
+	case *MapAction:
+		return a
+	case *ExtendMapAction:
+		return a.MapAction
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package argparse
 
+// MutuallyExclusiveGroup makes sure that only one of its actions is present
+// on the command line, and one of them if it is Required.
 type MutuallyExclusiveGroup struct {
 	*ActionsContainer
 	GroupActions []ActionInterface
+
+	container ActionsContainerInterface // The parser or argument group the actions are added to
 }
 
 func NewMutuallyExclusiveGroup(
@@ -19,17 +23,43 @@ func NewMutuallyExclusiveGroup(
 		conflictHandler,
 	)
 
-	return &MutuallyExclusiveGroup{
+	group := &MutuallyExclusiveGroup{
 		ActionsContainer: actionsContainer.(*ActionsContainer),
 	}
+	group.Container = group
+	return group
+}
+
+// bind makes container the container of the group's actions, the group
+// shares the attributes of shared, the container holding the actions.
+func (a *MutuallyExclusiveGroup) bind(container ActionsContainerInterface, shared *ActionsContainer) {
+	a.container = container
+	a.PrefixChars = shared.PrefixChars
+	a.ArgumentDefault = shared.ArgumentDefault
+	a.ConflictHandler = shared.ConflictHandler
+	a.Registries = shared.Registries
+	a.OptionStringActions = shared.OptionStringActions
+	a.Defaults = shared.Defaults
+	a.HasNegativeNumberOptionals = shared.HasNegativeNumberOptionals
 }
 
+// AddAction adds action to the container of the group and to the group.
 func (a *MutuallyExclusiveGroup) AddAction(action ActionInterface) ActionInterface {
-	return &Action{}
+	if action.Struct().Required {
+		panic("mutually exclusive arguments must be optional")
+	}
+	if a.container == nil {
+		panic("mutually exclusive group must be added to a container with AddMutuallyExclusiveGroup")
+	}
+	act := a.container.AddAction(action)
+	a.GroupActions = append(a.GroupActions, act)
+	return act
 }
 
+// RemoveAction removes action from the container of the group and from the group.
 func (a *MutuallyExclusiveGroup) RemoveAction(action ActionInterface) {
-
+	a.container.RemoveAction(action)
+	a.GroupActions = removeAction(a.GroupActions, action)
 }
 
 func (ag *MutuallyExclusiveGroup) AddArgumentGroup(argumentGroup ActionsContainerInterface) ActionsContainerInterface {
@@ -2,7 +2,10 @@ package argparse
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -11,12 +14,24 @@ import (
 type Namespace struct {
 	*AttributeHolder_
 	attributes map[string]any
+	sources    map[string]Source // The source of each value set by the parser
+	sensitive  map[string]bool   // The dests whose values are masked in Repr and the encodings
+
+	argIndex     int            // The index of the arguments of the action being taken, offsets the sources of subparsers
+	printConfig  bool           // Whether a PrintConfigAction was taken
+	printVersion *VersionAction // The VersionAction taken, if any
+	versionJSON  bool           // Whether a VersionJSONAction was taken
+	plugin       *pluginCommand // The plugin subcommand taken, if any
 }
 
 // NewNamespace creates a new Namespace with the given attributes.
 func NewNamespace(attributes map[string]any) *Namespace {
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
 	return &Namespace{
 		attributes: attributes,
+		sources:    make(map[string]Source),
 	}
 }
 
@@ -31,6 +46,45 @@ func (n *Namespace) Get(name string) (any, bool) {
 	return val, found
 }
 
+// Source returns where the value of name comes from, found is false if the
+// value was not set by the parser.
+func (n *Namespace) Source(name string) (source Source, found bool) {
+	source, found = n.sources[name]
+	return source, found
+}
+
+// setSource records the source of the value of name.
+func (n *Namespace) setSource(name string, source Source) {
+	if n.sources == nil {
+		n.sources = make(map[string]Source)
+	}
+	n.sources[name] = source
+}
+
+// MarkSensitive masks the value of name in Repr and the encodings of the
+// Namespace, the parser marks the dests of the Sensitive arguments.
+func (n *Namespace) MarkSensitive(name string) {
+	if n.sensitive == nil {
+		n.sensitive = make(map[string]bool)
+	}
+	n.sensitive[name] = true
+}
+
+// IsSensitive reports whether the value of name is masked.
+func (n *Namespace) IsSensitive(name string) bool {
+	return n.sensitive[name]
+}
+
+// maskedValue returns the value of name, SENSITIVE_MASK if it is sensitive
+// and not nil.
+func (n *Namespace) maskedValue(name string) any {
+	value := n.attributes[name]
+	if value != nil && n.sensitive[name] {
+		return SENSITIVE_MASK
+	}
+	return value
+}
+
 // Equals compares two Namespace objects for equality based on attribute names and values.
 func (n *Namespace) Equals(other *Namespace) bool {
 	if other == nil {
@@ -45,13 +99,181 @@ func (n *Namespace) Contains(key string) bool {
 	return found
 }
 
-// Repr returns a string representation of the Namespace with its attributes.
+// Keys returns the sorted names of the attributes.
+func (n *Namespace) Keys() []string {
+	keys := make([]string, 0, len(n.attributes))
+	for key := range n.attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Delete removes an attribute and its source from the Namespace.
+func (n *Namespace) Delete(name string) {
+	delete(n.attributes, name)
+	delete(n.sources, name)
+}
+
+// Merge sets the attributes of other, with their sources and sensitivity,
+// in the Namespace.
+func (n *Namespace) Merge(other *Namespace) {
+	for key, value := range other.attributes {
+		n.attributes[key] = cloneValue(value)
+	}
+	for key, source := range other.sources {
+		n.setSource(key, source)
+	}
+	for key := range other.sensitive {
+		n.MarkSensitive(key)
+	}
+}
+
+// Clone returns a copy of the Namespace, the lists and maps of its values
+// are copied too.
+func (n *Namespace) Clone() *Namespace {
+	clone := NewNamespace(nil)
+	clone.Merge(n)
+	return clone
+}
+
+// cloneValue copies the []any and map[string]any values recursively.
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = cloneValue(item)
+		}
+		return items
+	case map[string]any:
+		items := make(map[string]any, len(v))
+		for key, item := range v {
+			items[key] = cloneValue(item)
+		}
+		return items
+	case *Namespace:
+		return v.Clone()
+	}
+	return value
+}
+
+// Repr returns a string representation of the Namespace like Python's,
+// the attributes sorted by name and their values in Python syntax, e.g.
+// Namespace(foo='bar', verbose=True, **{'log-level': None}). The values of
+// sensitive dests are masked.
 func (n *Namespace) Repr() string {
-	// Collect the attribute name and value pairs.
 	var argStrings []string
-	for key, value := range n.attributes {
-		argStrings = append(argStrings, fmt.Sprintf("%s=%v", key, value))
+	starArgs := []string{}
+	for _, key := range n.Keys() {
+		if isValidIdentifier(key) {
+			argStrings = append(argStrings, fmt.Sprintf("%s=%s", key, pyRepr(n.maskedValue(key))))
+		} else {
+			starArgs = append(starArgs, fmt.Sprintf("%s: %s", pyRepr(key), pyRepr(n.maskedValue(key))))
+		}
+	}
+	if len(starArgs) > 0 {
+		argStrings = append(argStrings, fmt.Sprintf("**{%s}", strings.Join(starArgs, ", ")))
 	}
 
 	return fmt.Sprintf("Namespace(%s)", strings.Join(argStrings, ", "))
 }
+
+// pyRepr formats value like Python's repr.
+func pyRepr(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		return pyQuote(v)
+	case float32:
+		return pyFloat(float64(v))
+	case float64:
+		return pyFloat(v)
+	case *Namespace:
+		return v.Repr()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, reflected.Len())
+		for i := range items {
+			items[i] = pyRepr(reflected.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		items := make([]string, 0, reflected.Len())
+		for _, key := range reflected.MapKeys() {
+			items = append(items, fmt.Sprintf("%s: %s", pyRepr(key.Interface()), pyRepr(reflected.MapIndex(key).Interface())))
+		}
+		sort.Strings(items)
+		return "{" + strings.Join(items, ", ") + "}"
+	case reflect.Pointer:
+		if reflected.IsNil() {
+			return "None"
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// pyQuote quotes s like Python's repr, with single quotes unless s
+// contains single quotes and no double quotes.
+func pyQuote(s string) string {
+	quote := byte('\'')
+	if strings.Contains(s, "'") && !strings.Contains(s, `"`) {
+		quote = '"'
+	}
+	var builder strings.Builder
+	builder.WriteByte(quote)
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == rune(quote):
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&builder, `\x%02x`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte(quote)
+	return builder.String()
+}
+
+// pyFloat formats f like Python's repr, e.g. 1.0, 1e+20, inf and nan.
+func pyFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	formatted := strconv.FormatFloat(f, 'g', -1, 64)
+	if exponent := strings.IndexByte(formatted, 'e'); exponent >= 0 {
+		// Python only uses the exponent below 1e-4 and from 1e16
+		if math.Abs(f) < 1e16 && math.Abs(f) >= 1e-4 {
+			formatted = strconv.FormatFloat(f, 'f', -1, 64)
+		} else {
+			return formatted
+		}
+	}
+	if !strings.ContainsAny(formatted, ".e") {
+		formatted += ".0"
+	}
+	return formatted
+}
@@ -0,0 +1,110 @@
+package argparse
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Get returns the value of the attribute name of namespace converted to T
+// like Decode converts the values of struct fields, e.g. the []any of an
+// append action to []string. A nil value, such as the default of a count
+// action, is the zero value of T. The error is a *DecodeError if the value
+// is not compatible with T.
+//
+//	ports, err := argparse.Get[[]uint16](ns, "port")
+func Get[T any](namespace *Namespace, name string) (T, error) {
+	var result T
+	value, found := namespace.Get(name)
+	if !found {
+		return result, fmt.Errorf("'Namespace' object has no attribute '%s'", name)
+	}
+	if err := decodeValue(value, reflect.ValueOf(&result).Elem(), reflect.TypeFor[T]().String()); err != nil {
+		err.Dest = name
+		return result, err
+	}
+	return result, nil
+}
+
+// MustGet is like Get but panics if the attribute is missing or has an
+// incompatible type.
+func MustGet[T any](namespace *Namespace, name string) T {
+	result, err := Get[T](namespace, name)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// GetString returns the value of the attribute name as a string.
+func (n *Namespace) GetString(name string) (string, error) {
+	return Get[string](n, name)
+}
+
+// GetInt returns the value of the attribute name as an int, e.g. the
+// number of occurrences counted by a count action.
+func (n *Namespace) GetInt(name string) (int, error) {
+	return Get[int](n, name)
+}
+
+// GetFloat returns the value of the attribute name as a float64.
+func (n *Namespace) GetFloat(name string) (float64, error) {
+	return Get[float64](n, name)
+}
+
+// GetBool returns the value of the attribute name as a bool.
+func (n *Namespace) GetBool(name string) (bool, error) {
+	return Get[bool](n, name)
+}
+
+// GetDuration returns the value of the attribute name as a time.Duration,
+// strings are parsed with time.ParseDuration.
+func (n *Namespace) GetDuration(name string) (time.Duration, error) {
+	return Get[time.Duration](n, name)
+}
+
+// GetStrings returns the values of the attribute name as a []string, e.g.
+// the values collected by an append or extend action.
+func (n *Namespace) GetStrings(name string) ([]string, error) {
+	return Get[[]string](n, name)
+}
+
+// GetInts returns the values of the attribute name as an []int.
+func (n *Namespace) GetInts(name string) ([]int, error) {
+	return Get[[]int](n, name)
+}
+
+// MustGetString is like GetString but panics on error.
+func (n *Namespace) MustGetString(name string) string {
+	return MustGet[string](n, name)
+}
+
+// MustGetInt is like GetInt but panics on error.
+func (n *Namespace) MustGetInt(name string) int {
+	return MustGet[int](n, name)
+}
+
+// MustGetFloat is like GetFloat but panics on error.
+func (n *Namespace) MustGetFloat(name string) float64 {
+	return MustGet[float64](n, name)
+}
+
+// MustGetBool is like GetBool but panics on error.
+func (n *Namespace) MustGetBool(name string) bool {
+	return MustGet[bool](n, name)
+}
+
+// MustGetDuration is like GetDuration but panics on error.
+func (n *Namespace) MustGetDuration(name string) time.Duration {
+	return MustGet[time.Duration](n, name)
+}
+
+// MustGetStrings is like GetStrings but panics on error.
+func (n *Namespace) MustGetStrings(name string) []string {
+	return MustGet[[]string](n, name)
+}
+
+// MustGetInts is like GetInts but panics on error.
+func (n *Namespace) MustGetInts(name string) []int {
+	return MustGet[[]int](n, name)
+}
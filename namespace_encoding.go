@@ -0,0 +1,250 @@
+package argparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MarshalJSON implements json.Marshaler, the attributes are encoded as an
+// object with sorted keys and the sensitive values masked.
+func (n *Namespace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.maskedAttributes())
+}
+
+// maskedAttributes returns the attributes with the sensitive values masked.
+func (n *Namespace) maskedAttributes() map[string]any {
+	if len(n.sensitive) == 0 {
+		return n.attributes
+	}
+	attributes := make(map[string]any, len(n.attributes))
+	for key := range n.attributes {
+		attributes[key] = n.maskedValue(key)
+	}
+	return attributes
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the attributes with
+// the members of a JSON object. Integers are decoded as int like the values
+// of the "int" type, other numbers as float64 and arrays as []any.
+func (n *Namespace) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var attributes map[string]any
+	if err := decoder.Decode(&attributes); err != nil {
+		return err
+	}
+	if attributes == nil {
+		return fmt.Errorf("cannot unmarshal null into a Namespace")
+	}
+	for key, value := range attributes {
+		attributes[key] = jsonValue(value)
+	}
+	n.attributes = attributes
+	n.sources = make(map[string]Source)
+	return nil
+}
+
+// jsonValue converts the numbers of a decoded JSON value.
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if integer, err := strconv.ParseInt(string(v), 10, strconv.IntSize); err == nil {
+			return int(integer)
+		}
+		float, _ := v.Float64()
+		return float
+	case []any:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+	case map[string]any:
+		for key, item := range v {
+			v[key] = jsonValue(item)
+		}
+	}
+	return value
+}
+
+// yamlPlainMatcher matches the strings that need no quotes in YAML.
+var yamlPlainMatcher = regexp.MustCompile(`^[A-Za-z_/.][A-Za-z0-9_ ./+-]*$`)
+
+// YAML encodes the attributes as a YAML mapping with sorted keys and the
+// sensitive values masked. Lists of
+// scalars and nested namespaces or maps are written as block sequences and
+// mappings, which ParseYAMLConfig reads back.
+func (n *Namespace) YAML() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := writeYAMLMapping(&buffer, n.maskedAttributes(), 0); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// writeYAMLMapping writes the entries of mapping at indent.
+func writeYAMLMapping(buffer *bytes.Buffer, mapping map[string]any, indent int) error {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	prefix := strings.Repeat(" ", indent)
+	for _, key := range keys {
+		fmt.Fprintf(buffer, "%s%s:", prefix, yamlScalar(key))
+		switch value := yamlNested(mapping[key]).(type) {
+		case map[string]any:
+			if len(value) == 0 {
+				buffer.WriteString(" {}\n")
+				continue
+			}
+			buffer.WriteString("\n")
+			if err := writeYAMLMapping(buffer, value, indent+2); err != nil {
+				return err
+			}
+		case []any:
+			if len(value) == 0 {
+				buffer.WriteString(" []\n")
+				continue
+			}
+			buffer.WriteString("\n")
+			for _, item := range value {
+				scalar, err := yamlFlow(item)
+				if err != nil {
+					return fmt.Errorf("%s: %v", key, err)
+				}
+				fmt.Fprintf(buffer, "%s  - %s\n", prefix, scalar)
+			}
+		default:
+			scalar, err := yamlFlow(value)
+			if err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+			fmt.Fprintf(buffer, " %s\n", scalar)
+		}
+	}
+	return nil
+}
+
+// yamlNested converts namespaces, slices and maps with string keys to
+// map[string]any and []any, other values are returned unchanged.
+func yamlNested(value any) any {
+	if namespace, ok := value.(*Namespace); ok {
+		return namespace.maskedAttributes()
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array:
+		if _, ok := value.([]byte); ok {
+			return value
+		}
+		items := make([]any, reflected.Len())
+		for i := range items {
+			items[i] = reflected.Index(i).Interface()
+		}
+		return items
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		items := make(map[string]any, reflected.Len())
+		for _, key := range reflected.MapKeys() {
+			items[key.String()] = reflected.MapIndex(key).Interface()
+		}
+		return items
+	}
+	return value
+}
+
+// yamlFlow formats a value on a single line, nested lists and mappings in
+// the flow style.
+func yamlFlow(value any) (string, error) {
+	switch v := yamlNested(value).(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			formatted, err := yamlFlow(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = formatted
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			formatted, err := yamlFlow(v[key])
+			if err != nil {
+				return "", err
+			}
+			items[i] = yamlScalar(key) + ": " + formatted
+		}
+		return "{" + strings.Join(items, ", ") + "}", nil
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+	return yamlScalar(value), nil
+}
+
+// yamlScalar formats a scalar, strings are quoted if they could be read
+// as another type.
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return yamlFloat(float64(v))
+	case float64:
+		return yamlFloat(v)
+	case string:
+		if yamlPlainMatcher.MatchString(v) && !strings.HasSuffix(v, " ") {
+			if parsed, err := parseYAMLScalar(v); err == nil && parsed == v {
+				return v
+			}
+		}
+		return strconv.Quote(v)
+	case fmt.Stringer:
+		return yamlScalar(v.String())
+	}
+
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%d", value)
+	case reflect.Pointer:
+		if reflected.IsNil() {
+			return "null"
+		}
+		return yamlScalar(reflected.Elem().Interface())
+	}
+	return strconv.Quote(fmt.Sprintf("%v", value))
+}
+
+// yamlFloat formats a float, with .inf and .nan for the special values.
+func yamlFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	return pyFloat(f)
+}
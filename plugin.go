@@ -0,0 +1,190 @@
+package argparse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// pluginCommand is a plugin subcommand taken by a SubParsersAction, run
+// once the parent parser has parsed its arguments.
+type pluginCommand struct {
+	Name   string          // The subcommand name
+	Path   string          // The executable
+	Args   []string        // The remaining arguments, passed to the executable
+	Parser *ArgumentParser // The parser whose option values are passed in the environment
+}
+
+// EnablePlugins makes the executables named prefix followed by a command
+// name found in PATH subcommands, e.g. mytool-foo runs for "mytool foo" with
+// the prefix "mytool-". The parsers added with AddParser take precedence.
+func (p *SubParsersAction) EnablePlugins(prefix string) {
+	p.PluginPrefix = prefix
+	p.Plugins = DiscoverPlugins(prefix)
+
+	names := make([]string, 0, len(p.Plugins))
+	for name := range p.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, exist := p.NameParserMap[name]; exist {
+			delete(p.Plugins, name)
+			continue
+		}
+		p.Choices = append(p.Choices, name)
+		p.ChoicesActions = append(p.ChoicesActions, NewChoicesPseudoAction(name, nil, "plugin "+filepath.Base(p.Plugins[name])))
+	}
+}
+
+// removePlugin removes the plugin subcommand name, replaced by a parser.
+func (p *SubParsersAction) removePlugin(name string) {
+	if _, exist := p.Plugins[name]; !exist {
+		return
+	}
+	delete(p.Plugins, name)
+	for i, choice := range p.Choices {
+		if choice == name {
+			p.Choices = append(p.Choices[:i:i], p.Choices[i+1:]...)
+			break
+		}
+	}
+	for i, choiceAction := range p.ChoicesActions {
+		if choiceAction.Struct().Dest == name {
+			p.ChoicesActions = append(p.ChoicesActions[:i:i], p.ChoicesActions[i+1:]...)
+			break
+		}
+	}
+}
+
+// DiscoverPlugins returns the executables in PATH whose name starts with
+// prefix by command name, the first one found wins. The extensions of
+// PATHEXT are removed on Windows. Empty and relative directories are
+// skipped, as by exec.LookPath, not to run the executables of the current
+// directory.
+func DiscoverPlugins(prefix string) map[string]string {
+	plugins := make(map[string]string)
+	if prefix == "" {
+		return plugins
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, found := strings.CutPrefix(entry.Name(), prefix)
+			if !found {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			name, executable := pluginName(name, path)
+			if _, exist := plugins[name]; !exist && name != "" && executable {
+				plugins[name] = path
+			}
+		}
+	}
+	return plugins
+}
+
+// pluginName returns the command name of the executable path, without its
+// extension on Windows, and whether path is an executable file.
+func pluginName(name string, path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	if runtime.GOOS != "windows" {
+		return name, info.Mode().Perm()&0o111 != 0
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	for _, executableExt := range strings.Split(strings.ToLower(pathExt), ";") {
+		if ext != "" && ext == executableExt {
+			return strings.TrimSuffix(name, filepath.Ext(name)), true
+		}
+	}
+	return "", false
+}
+
+// runPlugin runs the plugin subcommand taken in namespace, if any, with the
+// streams of the parser and the values of the options of its parser in
+// their environment variables, then exits with its status.
+func (ap *ArgumentParser) runPlugin(namespace *Namespace) error {
+	plugin := namespace.plugin
+	if plugin == nil {
+		return nil
+	}
+	namespace.plugin = nil
+
+	cmd := exec.Command(plugin.Path, plugin.Args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = ap.stdin(), ap.stdout(), ap.stderr()
+	cmd.Env = append(os.Environ(), plugin.Parser.PluginEnv_(namespace)...)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ap.Exit(exitErr.ExitCode(), "")
+	} else if err != nil {
+		return fmt.Errorf("can't run plugin '%s': %v", plugin.Name, err)
+	}
+	ap.Exit(0, "")
+	return nil
+}
+
+// PluginEnv_ returns the values of the options in namespace as the
+// NAME=value environment variables read by the parser, with EnvPrefix or the
+// upper case program name as prefix. Lists are joined with LIST_SEPARATOR,
+// maps as their key=value items. The sensitive values are not exported.
+func (ap *ArgumentParser) PluginEnv_(namespace *Namespace) []string {
+	prefix := ap.EnvPrefix
+	if prog := strings.Fields(ap.Prog); prefix == "" && len(prog) > 0 {
+		prefix = strings.ToUpper(identifierMatcher.ReplaceAllString(prog[len(prog)-1], "_")) + "_"
+	}
+
+	var env []string
+	for _, action := range ap.Actions {
+		dest := action.Struct().Dest
+		name := envName(action, prefix)
+		value, found := namespace.Get(dest)
+		if name == "" || !found || value == nil || action.Struct().Sensitive || namespace.IsSensitive(dest) {
+			continue
+		}
+		env = append(env, name+"="+envValue(value))
+	}
+	return env
+}
+
+// envValue formats value for an environment variable, as read back by the
+// actions: the items of a list or the key=value items of a map, sorted by
+// key, joined with LIST_SEPARATOR.
+func envValue(value any) string {
+	rv := reflect.ValueOf(value)
+	var items []string
+	switch rv.Kind() {
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, fmt.Sprintf("%v", rv.Index(i).Interface()))
+		}
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			items = append(items, fmt.Sprintf("%v=%v", key.Interface(), rv.MapIndex(key).Interface()))
+		}
+		sort.Strings(items)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.Join(items, LIST_SEPARATOR)
+}
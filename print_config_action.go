@@ -0,0 +1,38 @@
+package argparse
+
+// PrintConfigAction prints the parsed values and their sources and exits,
+// e.g. --print-config. Unlike help, the values are printed by ParseArgs once
+// all the arguments, environment variables and configuration files are parsed.
+type PrintConfigAction struct {
+	*Action
+}
+
+// NewPrintConfigAction creates a new PrintConfigAction.
+func NewPrintConfigAction(argument *Argument) ActionInterface {
+	if argument.Dest == "" {
+		argument.Dest = SUPPRESS
+	}
+	if argument.Default == nil {
+		argument.Default = SUPPRESS
+	}
+	if argument.Help == "" {
+		argument.Help = "show the configuration and exit"
+	}
+
+	return &PrintConfigAction{
+		Action: &Action{
+			OptionStrings: argument.OptionStrings,
+			Dest:          argument.Dest,
+			Nargs:         0,
+			Default:       argument.Default,
+			Help:          argument.Help,
+			Deprecated:    argument.Deprecated,
+		},
+	}
+}
+
+// Call requests the namespace to be printed at the end of parsing.
+func (a *PrintConfigAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	namespace.printConfig = true
+	return nil
+}
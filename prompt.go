@@ -0,0 +1,149 @@
+package argparse
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Prompter asks the user for the values of the missing arguments with a
+// Prompt.
+type Prompter interface {
+	Interactive() bool                               // Whether the user can be asked, e.g. stdin is a terminal
+	Ask(message string, hidden bool) (string, error) // Shows message and reads the answer, without echo if hidden
+	Tell(message string)                             // Shows a message, e.g. why an answer is invalid
+}
+
+// TerminalPrompter is a Prompter reading the answers from a terminal.
+type TerminalPrompter struct {
+	In  io.Reader // The terminal, usually os.Stdin
+	Out io.Writer // Where the questions are written, usually os.Stderr
+
+	reader *bufio.Reader
+}
+
+// NewTerminalPrompter creates a new TerminalPrompter, nil in and out are
+// os.Stdin and os.Stderr.
+func NewTerminalPrompter(in io.Reader, out io.Writer) *TerminalPrompter {
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+	return &TerminalPrompter{In: in, Out: out}
+}
+
+// Interactive reports whether In is a terminal.
+func (p *TerminalPrompter) Interactive() bool {
+	file, ok := p.In.(*os.File)
+	return ok && isTerminal(file)
+}
+
+// Ask writes message to Out and reads a line from In, turning the echo of
+// the terminal off if hidden and supported.
+func (p *TerminalPrompter) Ask(message string, hidden bool) (string, error) {
+	if p.reader == nil {
+		p.reader = bufio.NewReader(p.In)
+	}
+	fmt.Fprint(p.Out, message)
+	file, _ := p.In.(*os.File)
+	if hidden && file != nil && setEcho(file, false) == nil {
+		defer func() {
+			setEcho(file, true)
+			fmt.Fprintln(p.Out)
+		}()
+	}
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Tell writes message to Out.
+func (p *TerminalPrompter) Tell(message string) {
+	fmt.Fprintln(p.Out, message)
+}
+
+// PromptMessage_ returns the question asked for action: its help, then its
+// Prompt with the choices and the default, e.g. "Format (text, json) [text]: ".
+// Flags are confirmed with y or n.
+func (ap *ArgumentParser) PromptMessage_(action ActionInterface) string {
+	act := action.Struct()
+	var message strings.Builder
+	if help := strings.TrimSpace(act.Help); help != "" && help != SUPPRESS {
+		message.WriteString(ap.GetFormatter_().ExpandHelp_(action) + "\n")
+	}
+	message.WriteString(act.Prompt)
+	if act.Nargs == 0 {
+		message.WriteString(" [y/N]: ")
+		return message.String()
+	}
+	if len(act.Choices) > 0 {
+		choices := make([]string, len(act.Choices))
+		for i, choice := range act.Choices {
+			choices[i] = fmt.Sprintf("%v", choice)
+		}
+		message.WriteString(fmt.Sprintf(" (%s)", strings.Join(choices, ", ")))
+	}
+	if act.Default != nil && act.Default != SUPPRESS && !act.PromptHidden && !act.Sensitive {
+		message.WriteString(fmt.Sprintf(" [%s]", formatValue(act.Default)))
+	}
+	message.WriteString(": ")
+	return message.String()
+}
+
+// PromptAction_ asks for the value of action until all its items are
+// converted and checked, then takes them with take. An empty answer keeps
+// the default of optional arguments and of flags, found is false if the
+// action is not taken.
+func (ap *ArgumentParser) PromptAction_(action ActionInterface, take func(argStrings []string, values any) error) (found bool, err error) {
+	act := action.Struct()
+	for {
+		answer, err := ap.Prompter.Ask(ap.PromptMessage_(action), act.PromptHidden || act.Sensitive)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			return false, err
+		}
+		if strings.TrimSpace(answer) == "" {
+			if !act.Required || act.Nargs == 0 {
+				return false, nil
+			}
+			ap.Prompter.Tell("error: a value is required")
+			continue
+		}
+
+		// the answers are converted as values from the environment, none
+		// is taken unless all of them are valid
+		argStrings, err := ap.EnvArgStrings_(action, answer)
+		values := make([]any, len(argStrings))
+		for i, args := range argStrings {
+			if err != nil {
+				break
+			}
+			if values[i], err = ap.GetValues(action, args); err != nil {
+				err = maskError(action, args, err)
+			}
+		}
+		var argumentErr *ArgumentError
+		if errors.As(err, &argumentErr) {
+			ap.Prompter.Tell("error: " + argumentErr.Message)
+			continue
+		} else if err != nil {
+			return false, err
+		}
+
+		for i, args := range argStrings {
+			if err := take(args, values[i]); err != nil {
+				return false, err
+			}
+		}
+		return len(argStrings) > 0, nil
+	}
+}
@@ -0,0 +1,296 @@
+package argparse
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// REPL reads commands, tokenizes them with shell quoting and runs them
+// through a parser and a handler, e.g. for a "mytool shell" subcommand. On a
+// terminal, lines are edited with history and tab completion and errors are
+// reported without exiting. Otherwise the commands are read from the Stdin
+// of the parser as a script, which stops at the first failing command.
+type REPL struct {
+	Parser      *ArgumentParser                  // Parses the commands
+	Handler     func(namespace *Namespace) error // Runs the parsed commands
+	Prompt      string                           // The prompt on a terminal, "prog> " by default
+	History     []string                         // The commands entered on the terminal, oldest first
+	HistoryFile string                           // Where the history is loaded from and saved to, if not empty
+	HistorySize int                              // The maximum number of commands kept in History, 1000 by default
+}
+
+// NewREPL creates a new REPL running the commands parsed by parser with handler.
+func NewREPL(parser *ArgumentParser, handler func(namespace *Namespace) error) *REPL {
+	return &REPL{
+		Parser:      parser,
+		Handler:     handler,
+		Prompt:      parser.Prog + "> ",
+		HistorySize: 1000,
+	}
+}
+
+// errREPLExit is returned by Execute for the exit and quit built-ins.
+var errREPLExit = errors.New("exit")
+
+// replExit unwinds a command whose parser exits with a status.
+type replExit struct {
+	status int
+}
+
+// Run reads and runs the commands until the end of the input or the exit
+// built-in. On a terminal the history is saved to HistoryFile, otherwise the
+// error of the first failing command is returned.
+func (r *REPL) Run() error {
+	file, _ := r.Parser.stdin().(*os.File)
+	if file == nil || !isTerminal(file) {
+		return r.runScript()
+	}
+
+	r.loadHistory()
+	defer r.saveHistory()
+	editor := newLineEditor(file, r.Parser.stderr(), r.CompleteLine)
+	for {
+		line, err := editor.ReadLine(r.Prompt, r.History)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		r.addHistory(line)
+		if err := r.Execute(line); errors.Is(err, errREPLExit) {
+			return nil
+		}
+	}
+}
+
+// runScript runs the commands read from the Stdin of the parser, skipping
+// the empty lines and the comments.
+func (r *REPL) runScript() error {
+	scanner := bufio.NewScanner(r.Parser.stdin())
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := r.Execute(line); errors.Is(err, errREPLExit) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Execute runs a command line: the help, history, exit and quit built-ins,
+// or the parsed arguments through Handler. Errors are reported on the Stderr
+// of the parser and returned, the parser and its subparsers do not exit.
+func (r *REPL) Execute(line string) (err error) {
+	parser := r.Parser
+	args, err := SplitCommandLine(line)
+	if err != nil {
+		parser.printMessage(fmt.Sprintf("%s: error: %s\n", parser.Prog, err), parser.stderr())
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return errREPLExit
+	case "history":
+		for i, command := range r.History {
+			parser.printMessage(fmt.Sprintf("%5d  %s\n", i+1, command), parser.stdout())
+		}
+		return nil
+	case "help":
+		return r.help(args[1:])
+	}
+
+	// the parsers exit after the help, the version or an error
+	defer func(hook func(int)) {
+		parser.exitHook = hook
+		if recovered := recover(); recovered != nil {
+			exit, ok := recovered.(replExit)
+			if !ok {
+				panic(recovered)
+			}
+			if exit.status != 0 {
+				err = fmt.Errorf("exit status %d", exit.status)
+			}
+		}
+	}(parser.exitHook)
+	parser.exitHook = func(status int) { panic(replExit{status}) }
+
+	namespace, err := parser.ParseArgs(args, nil)
+	if err == nil && r.Handler != nil {
+		err = r.Handler(namespace)
+	}
+	if err != nil {
+		parser.printMessage(fmt.Sprintf("%s: error: %s\n", parser.Prog, err), parser.stderr())
+	}
+	return err
+}
+
+// help prints the help of the parser, or of the subcommand names.
+func (r *REPL) help(names []string) error {
+	parser := r.Parser
+	for _, name := range names {
+		var subparser *ArgumentParser
+		if parser.Subparsers != nil {
+			subparser = parser.Subparsers.NameParserMap[name]
+		}
+		if subparser == nil {
+			err := fmt.Errorf("unknown command '%s'", name)
+			r.Parser.printMessage(fmt.Sprintf("%s: error: %s\n", r.Parser.Prog, err), r.Parser.stderr())
+			return err
+		}
+		parser = subparser
+	}
+	parser.PrintHelp(r.Parser.stdout())
+	r.Parser.printMessage("\nbuilt-in commands: help [command ...], history, exit, quit\n", r.Parser.stdout())
+	return nil
+}
+
+// CompleteLine completes the last word of line with the candidates of the
+// parser, as on tab: up to their common prefix, followed by a space if there
+// is a single candidate. The candidates are returned to be listed if none is
+// completed further.
+func (r *REPL) CompleteLine(line string) (string, []Candidate) {
+	args, err := SplitCommandLine(line)
+	if err != nil {
+		return line, nil
+	}
+	start := strings.LastIndexAny(line, " \t") + 1
+	word := line[start:]
+	if word == "" {
+		args = append(args, "")
+	}
+
+	candidates := r.Parser.Complete(args)
+	if len(candidates) == 0 {
+		return line, nil
+	}
+	common := candidates[0].Value
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate.Value, common) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	if len(candidates) == 1 {
+		completed := shellQuote(common)
+		if !strings.HasSuffix(common, string(os.PathSeparator)) {
+			completed += " "
+		}
+		return line[:start] + completed, nil
+	}
+	if len(common) > len(args[len(args)-1]) {
+		return line[:start] + common, nil
+	}
+	return line, candidates
+}
+
+// addHistory adds line to History, unless it repeats the last command.
+func (r *REPL) addHistory(line string) {
+	if len(r.History) > 0 && r.History[len(r.History)-1] == line {
+		return
+	}
+	r.History = append(r.History, line)
+	if r.HistorySize > 0 && len(r.History) > r.HistorySize {
+		r.History = r.History[len(r.History)-r.HistorySize:]
+	}
+}
+
+// loadHistory prepends the commands of HistoryFile to History.
+func (r *REPL) loadHistory() {
+	if r.HistoryFile == "" {
+		return
+	}
+	content, err := os.ReadFile(r.HistoryFile)
+	if err != nil {
+		return
+	}
+	history := r.History
+	r.History = nil
+	for _, line := range append(strings.Split(strings.TrimRight(string(content), "\n"), "\n"), history...) {
+		if line != "" {
+			r.addHistory(line)
+		}
+	}
+}
+
+// saveHistory writes History to HistoryFile.
+func (r *REPL) saveHistory() {
+	if r.HistoryFile == "" {
+		return
+	}
+	os.WriteFile(r.HistoryFile, []byte(strings.Join(r.History, "\n")+"\n"), 0o600)
+}
+
+// SplitCommandLine splits line into arguments with the POSIX shell quoting:
+// single quotes keep their content, double quotes keep it except for the
+// backslash escapes of \, ", $ and `, and a backslash escapes the next
+// character outside quotes. A # starting a word starts a comment.
+func SplitCommandLine(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '#' && !inArg:
+			return args, nil
+		case c == '\\':
+			inArg = true
+			if i+1 < len(line) {
+				i++
+				arg.WriteByte(line[i])
+			}
+		case c == '\'':
+			inArg = true
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in '%s'", line)
+			}
+			arg.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+		case c == '"':
+			inArg = true
+			closed := false
+			for i++; i < len(line); i++ {
+				if line[i] == '"' {
+					closed = true
+					break
+				}
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\\\"$`", line[i+1]) >= 0 {
+					i++
+				}
+				arg.WriteByte(line[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quote in '%s'", line)
+			}
+		default:
+			inArg = true
+			arg.WriteByte(c)
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
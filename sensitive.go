@@ -0,0 +1,150 @@
+package argparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SENSITIVE_MASK replaces the values of sensitive arguments in Repr, the
+// encodings of a Namespace, the help and the error messages.
+const SENSITIVE_MASK = "********"
+
+// maskError replaces the secrets in the message of an ArgumentError with
+// SENSITIVE_MASK if action is sensitive.
+func maskError(action ActionInterface, secrets []string, err error) error {
+	act := action.Struct()
+	var argumentErr *ArgumentError
+	if !act.Sensitive || !errors.As(err, &argumentErr) {
+		return err
+	}
+
+	// the items of separated arguments are secrets too
+	if act.Separator != "" {
+		for _, secret := range secrets {
+			if items, err := splitSeparated(secret, act.Separator); err == nil {
+				secrets = append(secrets, items...)
+			}
+		}
+	}
+	// and the values of KEY=VALUE arguments
+	if mapAction := mapActionOf(action); mapAction != nil {
+		for _, secret := range secrets {
+			if _, value, found := strings.Cut(secret, mapAction.KeySeparator); found {
+				secrets = append(secrets, value)
+			}
+		}
+	}
+	for _, secret := range secrets {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			argumentErr.Message = strings.ReplaceAll(argumentErr.Message, secret, SENSITIVE_MASK)
+		}
+	}
+	return err
+}
+
+// SecretFileAction reads the value of a sensitive argument from the file
+// named by its argument, e.g. --password-file, without its final newline.
+// The Type of the argument converts the content.
+type SecretFileAction struct {
+	*Action
+	values *Action
+}
+
+// NewSecretFileAction creates a new SecretFileAction, usually with the dest
+// of the argument it is a source of.
+func NewSecretFileAction(argument *Argument) ActionInterface {
+	action := &SecretFileAction{
+		Action: &Action{
+			OptionStrings: argument.OptionStrings,
+			Dest:          argument.Dest,
+			Default:       secretDefault(argument.Default),
+			Required:      argument.Required,
+			Help:          argument.Help,
+			MetaVar:       argument.MetaVar,
+			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
+			Completer:     argument.Completer,
+			Sensitive:     true,
+		},
+	}
+	action.values = &Action{OptionStrings: argument.OptionStrings, Dest: argument.Dest, Type: argument.Type, Choices: argument.Choices, Validate: argument.Validate, Sensitive: true}
+	return action
+}
+
+// Call reads the file named by values and stores its content.
+func (a *SecretFileAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	path := fmt.Sprintf("%v", values)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return NewArgumentError(a.Action, fmt.Sprintf("can't open '%s': %v", path, err))
+	}
+	return storeSecret(parser, namespace, a.values, string(content))
+}
+
+// SecretStdinAction reads the value of a sensitive argument from stdin,
+// e.g. --token-stdin, without its final newline. The Type of the argument
+// converts the content.
+type SecretStdinAction struct {
+	*Action
+	values *Action
+}
+
+// NewSecretStdinAction creates a new SecretStdinAction, usually with the
+// dest of the argument it is a source of.
+func NewSecretStdinAction(argument *Argument) ActionInterface {
+	action := &SecretStdinAction{
+		Action: &Action{
+			OptionStrings: argument.OptionStrings,
+			Dest:          argument.Dest,
+			Nargs:         0,
+			Default:       secretDefault(argument.Default),
+			Required:      argument.Required,
+			Help:          argument.Help,
+			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
+			Sensitive:     true,
+		},
+	}
+	action.values = &Action{OptionStrings: argument.OptionStrings, Dest: argument.Dest, Type: argument.Type, Choices: argument.Choices, Validate: argument.Validate, Sensitive: true}
+	return action
+}
+
+// Call reads the Stdin of parser and stores its content.
+func (a *SecretStdinAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	content, err := io.ReadAll(parser.stdin())
+	if err != nil {
+		return NewArgumentError(a.Action, fmt.Sprintf("can't read stdin: %v", err))
+	}
+	return storeSecret(parser, namespace, a.values, string(content))
+}
+
+// secretDefault returns the default of the secret actions, SUPPRESS unless
+// given so that the default of the argument they are a source of is kept.
+func secretDefault(defaultValue any) any {
+	if defaultValue == nil {
+		return SUPPRESS
+	}
+	return defaultValue
+}
+
+// storeSecret converts content without its final newline with the Type of
+// action and stores it.
+func storeSecret(parser *ArgumentParser, namespace *Namespace, action *Action, content string) error {
+	content = strings.TrimSuffix(strings.TrimSuffix(content, "\n"), "\r")
+	value, err := parser.GetValue(action, content)
+	if err == nil {
+		err = parser.CheckValue(action, value)
+	}
+	if err == nil {
+		err = validateValues(action, value)
+	}
+	if err != nil {
+		return maskError(action, []string{content}, err)
+	}
+	namespace.Set(action.Dest, value)
+	namespace.MarkSensitive(action.Dest)
+	return nil
+}
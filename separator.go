@@ -0,0 +1,68 @@
+package argparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitSeparated splits an arg string at separator, like a CSV record: a
+// field starting with a double quote extends to the closing quote, with ""
+// standing for a quote, and a backslash escapes the next character outside
+// quotes. An empty string has no fields.
+func splitSeparated(s string, separator string) ([]string, error) {
+	if s == "" {
+		return []string{}, nil
+	}
+
+	var fields []string
+	var field strings.Builder
+	quoted, inQuotes := false, false
+	for i := 0; i < len(s); {
+		switch {
+		case inQuotes:
+			if s[i] != '"' {
+				field.WriteByte(s[i])
+				i++
+			} else if strings.HasPrefix(s[i:], `""`) {
+				field.WriteByte('"')
+				i += 2
+			} else {
+				inQuotes = false
+				i++
+			}
+		case s[i] == '"' && field.Len() == 0 && !quoted:
+			quoted, inQuotes = true, true
+			i++
+		case s[i] == '\\' && i+1 < len(s):
+			field.WriteByte(s[i+1])
+			i += 2
+		case strings.HasPrefix(s[i:], separator):
+			fields = append(fields, field.String())
+			field.Reset()
+			quoted = false
+			i += len(separator)
+		case quoted:
+			return nil, fmt.Errorf("unexpected character after quoted field in '%s'", s)
+		default:
+			field.WriteByte(s[i])
+			i++
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in '%s'", s)
+	}
+	return append(fields, field.String()), nil
+}
+
+// joinSeparated joins fields with separator, quoting the fields that
+// splitSeparated would split or unescape.
+func joinSeparated(fields []string, separator string) string {
+	quotedFields := make([]string, len(fields))
+	for i, field := range fields {
+		if field == "" || strings.Contains(field, separator) || strings.ContainsAny(field, `"\`) {
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+		quotedFields[i] = field
+	}
+	return strings.Join(quotedFields, separator)
+}
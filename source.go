@@ -0,0 +1,76 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The kinds of sources of the values in a Namespace, from the lowest to the
+// highest precedence.
+const (
+	SOURCE_DEFAULT        = "default"
+	SOURCE_PARSER_DEFAULT = "parser default"
+	SOURCE_CONFIG         = "config file"
+	SOURCE_ENV            = "environment variable"
+	SOURCE_PROMPT         = "prompt"
+	SOURCE_COMMAND_LINE   = "command line"
+)
+
+// Source records where the value of a dest in a Namespace comes from.
+type Source struct {
+	Kind  string // One of the SOURCE_ constants
+	Name  string // The option string or positional name, environment variable or configuration file
+	Line  int    // The line in the configuration file
+	Index int    // The index of the option string or first argument in the parsed arguments
+}
+
+// String returns a description of the source, e.g. "config file app.toml:3".
+func (s Source) String() string {
+	switch s.Kind {
+	case SOURCE_CONFIG:
+		return fmt.Sprintf("%s %s:%d", s.Kind, s.Name, s.Line)
+	case SOURCE_ENV:
+		return fmt.Sprintf("%s %s", s.Kind, s.Name)
+	case SOURCE_COMMAND_LINE:
+		return fmt.Sprintf("%s %s (argument %d)", s.Kind, s.Name, s.Index)
+	}
+	return s.Kind
+}
+
+// FormatConfig returns the values of namespace sorted by dest, each
+// followed by its source.
+func (ap *ArgumentParser) FormatConfig(namespace *Namespace) string {
+	keys := make([]string, 0, len(namespace.attributes))
+	for key := range namespace.attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	width := 0
+	for i, key := range keys {
+		lines[i] = fmt.Sprintf("%s = %s", key, formatValue(namespace.maskedValue(key)))
+		width = max(width, len(lines[i]))
+	}
+
+	var builder strings.Builder
+	for i, key := range keys {
+		if source, found := namespace.Source(key); found {
+			fmt.Fprintf(&builder, "%-*s  # %s\n", width, lines[i], source)
+		} else {
+			builder.WriteString(lines[i] + "\n")
+		}
+	}
+	return builder.String()
+}
+
+// PrintConfig prints the values of namespace and their sources to file,
+// or Stdout if file is nil.
+func (ap *ArgumentParser) PrintConfig(namespace *Namespace, file io.Writer) {
+	if file == nil {
+		file = ap.stdout()
+	}
+	ap.printMessage(ap.FormatConfig(namespace), file)
+}
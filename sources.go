@@ -0,0 +1,124 @@
+package argparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LIST_SEPARATOR separates the items of a value from the environment or a
+// configuration file for the actions accepting several values, e.g.
+// MYTOOL_TAGS=a,b,c. Newlines separate items too.
+const LIST_SEPARATOR = ","
+
+// parseBool parses a boolean value from the environment or a configuration file.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "t", "yes", "y", "on":
+		return true, nil
+	case "0", "false", "f", "no", "n", "off", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value: '%s'", value)
+}
+
+// acceptsList reports whether action accepts several values, either by
+// consuming several arguments or by collecting them.
+func acceptsList(action ActionInterface) bool {
+	switch action.Struct().Nargs {
+	case 0:
+		return false
+	case nil, OPTIONAL:
+		switch action.(type) {
+		case *AppendAction, *ExtendAction, *MapAction, *ExtendMapAction:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// splitList splits value at LIST_SEPARATOR and newlines, dropping the empty items.
+func splitList(value string) []string {
+	items := []string{}
+	for _, line := range strings.Split(value, "\n") {
+		for _, item := range strings.Split(line, LIST_SEPARATOR) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// SourceArgStrings_ converts the items of a value from a source other than
+// the command line to the arg strings of each time the action is taken:
+// flags are taken once if the value is true (false for store_false), boolean
+// optional actions once with the value, count
+// actions the given number of times, append and extend actions of single
+// values once per item, and the other actions once with all the items.
+func (ap *ArgumentParser) SourceArgStrings_(action ActionInterface, items []string) ([][]string, error) {
+	act := action.Struct()
+
+	// options without arguments are flags
+	if act.Nargs == 0 {
+		if len(items) != 1 {
+			return nil, NewArgumentError(act, "expected one value")
+		}
+		if _, ok := action.(*CountAction); ok {
+			count, err := strconv.Atoi(strings.TrimSpace(items[0]))
+			if err != nil || count < 0 {
+				return nil, NewArgumentError(act, fmt.Sprintf("invalid count value: '%s'", items[0]))
+			}
+			return make([][]string, count), nil
+		}
+		set, err := parseBool(items[0])
+		if err != nil {
+			return nil, NewArgumentError(act, err.Error())
+		}
+		if _, ok := action.(*BooleanOptionalAction); ok {
+			return [][]string{{strconv.FormatBool(set)}}, nil
+		}
+		if _, ok := action.(*StoreFalseAction); ok {
+			set = !set
+		}
+		if !set {
+			return nil, nil
+		}
+		return [][]string{{}}, nil
+	}
+
+	// separated values are taken once, split by GetValues
+	if act.Separator != "" {
+		return [][]string{items}, nil
+	}
+
+	switch act.Nargs {
+	case nil, OPTIONAL:
+		// collecting single values takes the action once per item
+		if acceptsList(action) {
+			argStrings := [][]string{}
+			for _, item := range items {
+				argStrings = append(argStrings, []string{item})
+			}
+			return argStrings, nil
+		}
+		if len(items) != 1 {
+			return nil, NewArgumentError(act, "expected one argument")
+		}
+		return [][]string{items}, nil
+	}
+
+	// the other actions take all the items at once
+	switch nargs := act.Nargs.(type) {
+	case int:
+		if len(items) != nargs {
+			return nil, NewArgumentError(act, fmt.Sprintf("expected %d arguments", nargs))
+		}
+	case string:
+		if (nargs == ONE_OR_MORE || nargs == PARSER) && len(items) == 0 {
+			return nil, NewArgumentError(act, "expected at least one argument")
+		}
+	}
+	return [][]string{items}, nil
+}
@@ -0,0 +1,219 @@
+package argparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// SPEC_VERSION is the version of the JSON schema of Spec, incremented when
+// a field is renamed or removed.
+const SPEC_VERSION = 1
+
+// ParserSpec describes a parser in Spec.
+type ParserSpec struct {
+	SpecVersion             int              `json:"specVersion,omitempty"` // SPEC_VERSION, set for the root parser
+	Prog                    string           `json:"prog"`
+	Usage                   string           `json:"usage,omitempty"`
+	Description             string           `json:"description,omitempty"`
+	Epilog                  string           `json:"epilog,omitempty"`
+	PrefixChars             string           `json:"prefixChars"`
+	EnvPrefix               string           `json:"envPrefix,omitempty"`
+	Actions                 []ActionSpec     `json:"actions"`
+	Groups                  []GroupSpec      `json:"groups"`
+	MutuallyExclusiveGroups []MutexGroupSpec `json:"mutuallyExclusiveGroups"`
+	Subparsers              *SubparsersSpec  `json:"subparsers,omitempty"`
+}
+
+// ActionSpec describes an action in Spec. The values that can't be encoded
+// in JSON are formatted as strings, the sensitive defaults are masked.
+type ActionSpec struct {
+	Kind          string   `json:"kind"` // The registered action name, e.g. "store_true"
+	OptionStrings []string `json:"optionStrings"`
+	Dest          string   `json:"dest"`
+	Nargs         any      `json:"nargs"` // An int, one of the nargs constants or null for a single argument
+	Const         any      `json:"const,omitempty"`
+	Default       any      `json:"default,omitempty"`
+	Type          string   `json:"type"`
+	Choices       []any    `json:"choices,omitempty"`
+	Required      bool     `json:"required"`
+	Help          string   `json:"help,omitempty"`
+	MetaVar       any      `json:"metavar,omitempty"`
+	Deprecated    bool     `json:"deprecated"`
+	Env           string   `json:"env,omitempty"` // The environment variable read for the action
+	Sensitive     bool     `json:"sensitive,omitempty"`
+}
+
+// GroupSpec describes an argument group in Spec, its actions are indices
+// in the Actions of the parser.
+type GroupSpec struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Actions     []int  `json:"actions"`
+}
+
+// MutexGroupSpec describes a mutually exclusive group in Spec, its actions
+// are indices in the Actions of the parser.
+type MutexGroupSpec struct {
+	Required bool  `json:"required"`
+	Actions  []int `json:"actions"`
+}
+
+// SubparsersSpec describes the subcommands of a parser in Spec.
+type SubparsersSpec struct {
+	Action   int           `json:"action"` // The index of the subparsers action in the Actions of the parser
+	Commands []CommandSpec `json:"commands"`
+}
+
+// CommandSpec describes a subcommand in Spec, either a parser or a plugin.
+type CommandSpec struct {
+	Name       string      `json:"name"`
+	Aliases    []string    `json:"aliases,omitempty"`
+	Help       string      `json:"help,omitempty"`
+	Deprecated bool        `json:"deprecated"`
+	Plugin     string      `json:"plugin,omitempty"` // The executable of a plugin subcommand
+	Parser     *ParserSpec `json:"parser,omitempty"`
+}
+
+// Spec returns the description of the parser, its actions, groups and
+// nested subparsers as indented JSON, versioned by SPEC_VERSION, e.g. for
+// documentation generators or user interfaces building command lines.
+func (ap *ArgumentParser) Spec() ([]byte, error) {
+	spec := ap.ParserSpec_()
+	spec.SpecVersion = SPEC_VERSION
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// ParserSpec_ returns the ParserSpec of the parser.
+func (ap *ArgumentParser) ParserSpec_() *ParserSpec {
+	spec := &ParserSpec{
+		Prog:                    ap.Prog,
+		Usage:                   ap.Usage,
+		Description:             ap.Description,
+		Epilog:                  ap.Epilog,
+		PrefixChars:             ap.PrefixChars,
+		EnvPrefix:               ap.EnvPrefix,
+		Actions:                 []ActionSpec{},
+		Groups:                  []GroupSpec{},
+		MutuallyExclusiveGroups: []MutexGroupSpec{},
+	}
+
+	indices := make(map[ActionInterface]int)
+	for i, action := range ap.Actions {
+		indices[action] = i
+		spec.Actions = append(spec.Actions, ap.actionSpec(action))
+	}
+	actionIndices := func(actions []ActionInterface) []int {
+		result := []int{}
+		for _, action := range actions {
+			if i, found := indices[action]; found {
+				result = append(result, i)
+			}
+		}
+		return result
+	}
+
+	for _, groupInterface := range ap.ActionGroups {
+		if group, ok := groupInterface.(*ArgumentGroup); ok {
+			spec.Groups = append(spec.Groups, GroupSpec{Title: group.Title, Description: group.Description, Actions: actionIndices(group.GroupActions)})
+		}
+	}
+	for _, groupInterface := range ap.MutuallyExclusiveGroups {
+		if group, ok := groupInterface.(*MutuallyExclusiveGroup); ok {
+			spec.MutuallyExclusiveGroups = append(spec.MutuallyExclusiveGroups, MutexGroupSpec{Required: group.Required, Actions: actionIndices(group.GroupActions)})
+		}
+	}
+
+	if subparsers := ap.Subparsers; subparsers != nil {
+		spec.Subparsers = &SubparsersSpec{Action: indices[subparsers], Commands: subparsersCommands(subparsers)}
+	}
+	return spec
+}
+
+// actionSpec returns the ActionSpec of action.
+func (ap *ArgumentParser) actionSpec(action ActionInterface) ActionSpec {
+	act := action.Struct()
+	spec := ActionSpec{
+		Kind:          actionKind(action),
+		OptionStrings: append([]string{}, act.OptionStrings...),
+		Dest:          act.Dest,
+		Nargs:         act.Nargs,
+		Const:         specValue(act.Const),
+		Default:       specValue(act.Default),
+		Type:          typeName(act.Type),
+		Required:      act.Required,
+		Help:          act.Help,
+		MetaVar:       specValue(act.MetaVar),
+		Deprecated:    act.Deprecated,
+		Env:           envName(action, ap.EnvPrefix),
+		Sensitive:     act.Sensitive,
+	}
+	if act.Sensitive && act.Default != nil && act.Default != SUPPRESS {
+		spec.Default = SENSITIVE_MASK
+	}
+	if _, ok := action.(*SubParsersAction); !ok {
+		for _, choice := range act.Choices {
+			spec.Choices = append(spec.Choices, specValue(choice))
+		}
+	}
+	return spec
+}
+
+// subparsersCommands returns the CommandSpecs of the parsers and plugins
+// of subparsers, aliases are attached to the command of their parser.
+func subparsersCommands(subparsers *SubParsersAction) []CommandSpec {
+	helps := make(map[string]string)
+	for _, choiceAction := range subparsers.ChoicesActions {
+		helps[choiceAction.Struct().Dest] = choiceAction.Struct().Help
+	}
+
+	commands := []CommandSpec{}
+	byParser := make(map[*ArgumentParser]int)
+	for _, choice := range subparsers.Choices {
+		name := fmt.Sprintf("%v", choice)
+		parser := subparsers.NameParserMap[name]
+		if i, found := byParser[parser]; found && parser != nil {
+			commands[i].Aliases = append(commands[i].Aliases, name)
+			continue
+		}
+		_, deprecated := subparsers.Deprecated[name]
+		command := CommandSpec{Name: name, Help: helps[name], Deprecated: deprecated}
+		if parser != nil {
+			command.Parser = parser.ParserSpec_()
+			byParser[parser] = len(commands)
+		} else {
+			command.Plugin = subparsers.Plugins[name]
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// actionKind returns the name the action was registered with, or the name
+// of its type for the actions not added with AddArgument.
+func actionKind(action ActionInterface) string {
+	if kind := action.Struct().kind; kind != "" {
+		return kind
+	}
+	t := reflect.TypeOf(action)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// specValue returns value if it can be encoded in JSON, formatted as a
+// string otherwise, e.g. for functions or files.
+func specValue(value any) any {
+	if value == nil {
+		return nil
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Func, reflect.Chan, reflect.Pointer, reflect.UnsafePointer:
+		return fmt.Sprintf("%v", value)
+	}
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return value
+}
@@ -38,6 +38,13 @@ func NewStoreAction(argument *Argument) ActionInterface {
 			Help:          argument.Help,
 			MetaVar:       argument.MetaVar,
 			Deprecated:    argument.Deprecated,
+			Env:           argument.Env,
+			Prompt:        argument.Prompt,
+			PromptHidden:  argument.PromptHidden,
+			Sensitive:     argument.Sensitive,
+			Completer:     argument.Completer,
+			Validate:      argument.Validate,
+			Separator:     argument.Separator,
 		},
 	}
 }
@@ -20,6 +20,9 @@ func NewStoreConstAction(argument *Argument) ActionInterface {
 		Help:          argument.Help,
 		MetaVar:       argument.MetaVar,
 		Deprecated:    argument.Deprecated,
+		Env:           argument.Env,
+		Prompt:        argument.Prompt,
+		PromptHidden:  argument.PromptHidden,
 	}
 
 	return &StoreConstAction{Action: action}
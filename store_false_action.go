@@ -8,6 +8,9 @@ type StoreFalseAction struct {
 
 func NewStoreFalseAction(argument *Argument) ActionInterface {
 	argument.Const = false
+	if argument.Default == nil {
+		argument.Default = true
+	}
 	storeConstAction := NewStoreConstAction(argument)
 	return &StoreFalseAction{
 		StoreConstAction: storeConstAction.(*StoreConstAction),
@@ -7,6 +7,9 @@ type StoreTrueAction struct {
 
 func NewStoreTrueAction(argument *Argument) ActionInterface {
 	argument.Const = true
+	if argument.Default == nil {
+		argument.Default = false
+	}
 	storeConstAction := NewStoreConstAction(argument)
 	return &StoreTrueAction{
 		StoreConstAction: storeConstAction.(*StoreConstAction),
@@ -0,0 +1,350 @@
+package argparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// StructBinding binds the fields of a struct to the arguments added for
+// them by AddStruct, the parser fills the fields after each parse.
+type StructBinding struct {
+	fields      []fieldBinding
+	commandDest string
+	commands    []commandBinding
+}
+
+// fieldBinding is a struct field and the dest of its argument.
+type fieldBinding struct {
+	value reflect.Value
+	dest  string
+	path  string
+}
+
+// commandBinding is a subcommand field and the binding of its struct.
+type commandBinding struct {
+	names   []string
+	value   reflect.Value // The field, a struct or a pointer to a struct
+	target  reflect.Value // The pointer to the struct bound to the subparser
+	binding *StructBinding
+}
+
+// AddStruct adds the arguments declared by the fields of the struct target
+// points to, the parser fills the struct after parsing like the handles
+// returned by Add.
+//
+// Each exported field is an argument, configured by its tags:
+//
+//	argparse    the option strings, e.g. "-v,--verbose", or the positional name;
+//	            "--field-name" by default, "-" to skip the field
+//	action, nargs, const, default, choices (comma separated), required,
+//	help, metavar, dest, env, deprecated
+//	            the attributes of the Argument
+//
+// The type of the field selects the type conversion: strings, integers,
+// floats, booleans (store_true by default), time.Duration and the types
+// implementing encoding.TextUnmarshaler. Pointers are optional values,
+// slices collect the values (append for options, nargs "*" for positionals).
+// Non-zero fields are the defaults of their arguments.
+//
+// Nested structs are argument groups, titled by their group tag (the field
+// name by default) and described by their help tag, or mutually exclusive
+// groups if tagged mutex:"true". Embedded structs are parent parsers. A
+// struct whose fields are tagged command:"name" (with aliases, help and
+// deprecated tags) declares the subcommands, each field being the struct,
+// or pointer to the struct, of a subparser. Its dest, required, help,
+// metavar, title and description tags configure AddSubparsers.
+func (ap *ArgumentParser) AddStruct(target any) (*StructBinding, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct target must be a non-nil pointer to a struct, got %T", target)
+	}
+	binding := &StructBinding{}
+	if err := binding.addFields(ap, ap, value.Elem(), value.Elem().Type().Name()); err != nil {
+		return nil, err
+	}
+	ap.valueSetters = append(ap.valueSetters, binding.Fill)
+	return binding, nil
+}
+
+// addFields adds the arguments of the fields of the struct value to container.
+func (b *StructBinding) addFields(ap *ArgumentParser, container ActionsContainerInterface, value reflect.Value, path string) error {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := value.Field(i)
+		fieldPath := path + "." + field.Name
+		if field.Tag.Get("argparse") == "-" || !field.IsExported() {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct && !isValueType(field.Type) {
+			var err error
+			switch {
+			case field.Anonymous:
+				err = b.addParent(ap, fieldValue, fieldPath)
+			case isCommandsType(field.Type):
+				err = b.addCommands(ap, field, fieldValue, fieldPath)
+			case field.Tag.Get("mutex") == "true":
+				group := container.AddMutuallyExclusiveGroup(NewMutuallyExclusiveGroup("", "", nil, nil))
+				group.Struct().Required = field.Tag.Get("required") == "true"
+				err = b.addFields(ap, group, fieldValue, fieldPath)
+			default:
+				title := field.Tag.Get("group")
+				if title == "" {
+					title = field.Name
+				}
+				group := container.AddArgumentGroup(NewArgumentGroup(ap.ActionsContainer, title, field.Tag.Get("help"), "", nil, nil))
+				err = b.addFields(ap, group, fieldValue, fieldPath)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		argument, err := fieldArgument(container, field, fieldValue)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fieldPath, err)
+		}
+		action := container.AddArgument(argument)
+		if dest := action.Struct().Dest; dest != SUPPRESS {
+			b.fields = append(b.fields, fieldBinding{value: fieldValue, dest: dest, path: fieldPath})
+		}
+	}
+	return nil
+}
+
+// addParent adds the arguments of an embedded struct through a parent parser.
+func (b *StructBinding) addParent(ap *ArgumentParser, value reflect.Value, path string) error {
+	parent, err := NewArgumentParser(map[string]any{"prefixChars": ap.PrefixChars, "addHelp": false})
+	if err != nil {
+		return err
+	}
+	if err := b.addFields(parent, parent, value, path); err != nil {
+		return err
+	}
+	ap.AddContainerAction(parent)
+	for key, value := range parent.Defaults {
+		ap.Defaults[key] = value
+	}
+	return nil
+}
+
+// addCommands adds the subparsers of the fields of a subcommands struct.
+func (b *StructBinding) addCommands(ap *ArgumentParser, field reflect.StructField, value reflect.Value, path string) error {
+	if b.commands != nil {
+		return fmt.Errorf("%s: cannot have multiple subcommands fields", path)
+	}
+	b.commandDest = field.Tag.Get("dest")
+	if b.commandDest == "" {
+		b.commandDest = strings.ReplaceAll(kebabCase(field.Name), "-", "_")
+	}
+	kwargs := map[string]any{
+		"dest":     b.commandDest,
+		"required": field.Tag.Get("required") == "true",
+	}
+	for _, key := range []string{"help", "metavar", "title", "description"} {
+		if tag, found := field.Tag.Lookup(key); found {
+			kwargs[key] = tag
+		}
+	}
+	subparsers, err := ap.AddSubparsers(kwargs)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		commandField := value.Type().Field(i)
+		name, found := commandField.Tag.Lookup("command")
+		if !found || !commandField.IsExported() {
+			continue
+		}
+		commandType := commandField.Type
+		if commandType.Kind() == reflect.Pointer {
+			commandType = commandType.Elem()
+		}
+		if commandType.Kind() != reflect.Struct {
+			return fmt.Errorf("%s.%s: subcommands must be structs, got %s", path, commandField.Name, commandField.Type)
+		}
+
+		parserKwargs := map[string]any{}
+		var aliases []string
+		if tag := commandField.Tag.Get("aliases"); tag != "" {
+			aliases = strings.Split(tag, ",")
+			parserKwargs["aliases"] = aliases
+		}
+		for _, key := range []string{"help", "description"} {
+			if tag, found := commandField.Tag.Lookup(key); found {
+				parserKwargs[key] = tag
+			}
+		}
+		subparser, err := subparsers.AddParser(name, commandField.Tag.Get("deprecated") == "true", parserKwargs)
+		if err != nil {
+			return err
+		}
+
+		// pointer fields are set if the subcommand is selected
+		target := reflect.New(commandType)
+		if commandField.Type.Kind() == reflect.Struct {
+			target = value.Field(i).Addr()
+		}
+		binding := &StructBinding{}
+		if err := binding.addFields(subparser, subparser, target.Elem(), path+"."+commandField.Name); err != nil {
+			return err
+		}
+		b.commands = append(b.commands, commandBinding{
+			names:   append([]string{name}, aliases...),
+			value:   value.Field(i),
+			target:  target,
+			binding: binding,
+		})
+	}
+	return nil
+}
+
+// Fill stores the values of namespace in the fields of the struct, the
+// fields of unselected subcommands are set to nil. The parser calls it
+// after each parse; call it to fill the struct from another namespace.
+func (b *StructBinding) Fill(namespace *Namespace) error {
+	for _, field := range b.fields {
+		value, found := namespace.Get(field.dest)
+		if !found {
+			continue
+		}
+		if err := decodeValue(value, field.value, field.path); err != nil {
+			err.Dest = field.dest
+			return err
+		}
+	}
+
+	command, _ := namespace.Get(b.commandDest)
+	for _, binding := range b.commands {
+		if !containsString(binding.names, fmt.Sprintf("%v", command)) {
+			if binding.value.Kind() == reflect.Pointer {
+				binding.value.Set(reflect.Zero(binding.value.Type()))
+			}
+			continue
+		}
+		if err := binding.binding.Fill(namespace); err != nil {
+			return err
+		}
+		if binding.value.Kind() == reflect.Pointer {
+			binding.value.Set(binding.target)
+		}
+	}
+	return nil
+}
+
+// fieldArgument returns the argument declared by a struct field.
+func fieldArgument(container ActionsContainerInterface, field reflect.StructField, value reflect.Value) (*Argument, error) {
+	tag := field.Tag
+	argument := &Argument{
+		Action:     tag.Get("action"),
+		Dest:       tag.Get("dest"),
+		Help:       tag.Get("help"),
+		Env:        tag.Get("env"),
+		Separator:  tag.Get("separator"),
+		Prompt:     tag.Get("prompt"),
+		Sensitive:  tag.Get("sensitive") == "true",
+		Required:   tag.Get("required") == "true",
+		Deprecated: tag.Get("deprecated") == "true",
+	}
+	if optionStrings := tag.Get("argparse"); optionStrings != "" {
+		argument.OptionStrings = strings.Split(optionStrings, ",")
+		if containsString(argument.OptionStrings, "") {
+			return nil, fmt.Errorf("empty option string in argparse tag '%s'", optionStrings)
+		}
+	} else {
+		argument.OptionStrings = []string{strings.Repeat(container.Struct().PrefixChars[:1], 2) + kebabCase(field.Name)}
+	}
+	positional := !strings.Contains(container.Struct().PrefixChars, argument.OptionStrings[0][:1])
+	if metavar, found := tag.Lookup("metavar"); found {
+		argument.MetaVar = metavar
+	}
+	if nargs, found := tag.Lookup("nargs"); found {
+		if n, err := strconv.Atoi(nargs); err == nil {
+			argument.Nargs = n
+		} else {
+			argument.Nargs = nargs
+		}
+	}
+
+	valueType, err := inferArgument(argument, field.Type, positional)
+	if err != nil {
+		return nil, err
+	}
+	convert := func(s string) (any, error) {
+		if typeFunc, ok := argument.Type.(TypeFunc); ok {
+			return typeFunc(s)
+		}
+		if valueType.Kind() == reflect.Bool {
+			return parseBool(s)
+		}
+		return s, nil
+	}
+
+	if choices := tag.Get("choices"); choices != "" {
+		for _, choice := range strings.Split(choices, ",") {
+			converted, err := convert(choice)
+			if err != nil {
+				return nil, fmt.Errorf("invalid choice %s: %v", choice, err)
+			}
+			argument.Choices = append(argument.Choices, converted)
+		}
+	}
+	if constant, found := tag.Lookup("const"); found {
+		converted, err := convert(constant)
+		if err != nil {
+			return nil, fmt.Errorf("invalid const %s: %v", constant, err)
+		}
+		argument.Const = converted
+	}
+
+	// string defaults are converted by the parser, like the defaults of
+	// the other arguments
+	if defaultValue, found := tag.Lookup("default"); found {
+		argument.Default = defaultValue
+		if argument.Type == nil {
+			converted, err := convert(defaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default %s: %v", defaultValue, err)
+			}
+			argument.Default = converted
+		}
+	} else if !value.IsZero() {
+		argument.Default = value.Interface()
+	}
+
+	// positionals have no dest and no required keyword
+	if positional {
+		argument.Dest = ""
+		argument.Required = false
+	}
+	return argument, nil
+}
+
+// isCommandsType reports whether the struct type t declares subcommands.
+func isCommandsType(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, found := t.Field(i).Tag.Lookup("command"); found {
+			return true
+		}
+	}
+	return false
+}
+
+// kebabCase converts a Go name to an option name, e.g. HTTPPort to http-port.
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			builder.WriteByte('-')
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
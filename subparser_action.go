@@ -9,10 +9,15 @@ import (
 type SubParsersAction struct {
 	*Action        // Embedding Action to reuse functionality
 	ProgPrefix     string
-	ParserClass    func(kwargs any) (any, error)
-	NameParserMap  map[string]any
+	ParserClass    NewArgumentParserFunc
+	NameParserMap  map[string]*ArgumentParser
 	ChoicesActions []ActionInterface
 	Deprecated     map[string]struct{}
+	ExitOnError    bool              // The default exitOnError of the parsers, the one of the parent parser
+	PluginPrefix   string            // The prefix of the plugin executables, see EnablePlugins
+	Plugins        map[string]string // The executables of the plugin subcommands by name
+
+	owner *ArgumentParser // The parser of the action, whose streams the parsers use by default
 }
 
 type ChoicesPseudoAction struct {
@@ -48,110 +53,135 @@ func NewSubParsersAction(argument *Action) *SubParsersAction {
 			OptionStrings: argument.OptionStrings,
 			Dest:          argument.Dest,
 			Nargs:         PARSER,
+			Choices:       []any{},
 			Required:      argument.Required,
 			Help:          argument.Help,
 			MetaVar:       argument.MetaVar,
 		},
-		// ProgPrefix:     prog,
-		// ParserClass:    parserClass,
-		// NameParserMap:  make(map[string]any),
-		// ChoicesActions: []*ChoicesPseudoAction{},
-		// Deprecated:     make(map[string]struct{}),
+		ParserClass:    NewArgumentParser,
+		NameParserMap:  make(map[string]*ArgumentParser),
+		ChoicesActions: []ActionInterface{},
+		Deprecated:     make(map[string]struct{}),
+		ExitOnError:    true,
 	}
 }
 
-func (p *SubParsersAction) AddParser(name string, deprecated bool, kwargs map[string]any) (any, error) {
-	if _, exist := kwargs["prog"]; exist {
-		delete(kwargs, "help")
-	}
-	aliases, exist := kwargs["aliases"]
-	if !exist {
-		aliases = map[string]string{}
+// AddParser creates a new parser for the subcommand name, accepted kwargs are
+// aliases, help and the keyword arguments of the parser class.
+func (p *SubParsersAction) AddParser(name string, deprecated bool, kwargs map[string]any) (*ArgumentParser, error) {
+	// copy kwargs, so the caller's map is not modified
+	parserKwargs := make(map[string]any, len(kwargs))
+	for key, value := range kwargs {
+		parserKwargs[key] = value
 	}
 
-	aliasesLi, ok := aliases.([]string)
-	if !ok {
-		return nil, fmt.Errorf("wrong aliases type: %v", aliasesLi)
+	// set prog from the existing prefix
+	if prog, exist := parserKwargs["prog"]; !exist || prog == nil {
+		parserKwargs["prog"] = fmt.Sprintf("%s %s", p.ProgPrefix, name)
 	}
 
-	if exist {
-		delete(kwargs, "aliases")
+	// the parsers report their errors like the parent parser
+	if _, exist := parserKwargs["exitOnError"]; !exist {
+		parserKwargs["exitOnError"] = p.ExitOnError
 	}
-	for _name := range p.NameParserMap {
-		if name == _name {
-			return nil, fmt.Errorf("conflicting subparser: %s", name)
+	if p.owner != nil {
+		for key, stream := range map[string]any{"stdout": p.owner.Stdout, "stderr": p.owner.Stderr, "stdin": p.owner.Stdin} {
+			if _, exist := parserKwargs[key]; !exist && stream != nil {
+				parserKwargs[key] = stream
+			}
 		}
 	}
-	for _, alias := range aliasesLi {
-		for _name := range p.NameParserMap {
-			if alias == _name {
-				return nil, fmt.Errorf("conflicting subparser alias: %s", alias)
-			}
+
+	aliases, err := kwarg(parserKwargs, "aliases", []string{})
+	if err != nil {
+		return nil, err
+	}
+	delete(parserKwargs, "aliases")
+
+	if _, exist := p.NameParserMap[name]; exist {
+		return nil, NewArgumentError(p.Action, fmt.Sprintf("conflicting subparser: %s", name))
+	}
+	for _, alias := range aliases {
+		if _, exist := p.NameParserMap[alias]; exist {
+			return nil, NewArgumentError(p.Action, fmt.Sprintf("conflicting subparser alias: %s", alias))
 		}
 	}
 
 	// create a pseudo-action to hold the choice help
-	var choiceAction *ChoicesPseudoAction = nil
-	if help, exist := kwargs["help"]; exist {
-		switch t := help.(type) {
-		case string:
-			choiceAction = NewChoicesPseudoAction(name, aliasesLi, t)
-		default:
-			return nil, fmt.Errorf("wrong help type for action: %s, %v", name, t)
+	if _, exist := parserKwargs["help"]; exist {
+		help, err := kwarg(parserKwargs, "help", "")
+		if err != nil {
+			return nil, err
 		}
+		delete(parserKwargs, "help")
+		p.ChoicesActions = append(p.ChoicesActions, NewChoicesPseudoAction(name, aliases, help))
+	}
+
+	// the parsers replace the plugins
+	p.removePlugin(name)
+	for _, alias := range aliases {
+		p.removePlugin(alias)
 	}
 
 	// create the parser and add it to the map
-	parser, err := p.ParserClass(kwargs)
+	parser, err := p.ParserClass(parserKwargs)
 	if err != nil {
-		return nil, fmt.Errorf("error on parser creating")
-	}
-	if choiceAction != nil {
-		// parser.CheckHelp(choiceAction)
-		fmt.Printf("WARNING on CheckHelp")
+		return nil, err
 	}
 	p.NameParserMap[name] = parser
+	p.Choices = append(p.Choices, name)
 
-	//  make parser available under aliases also
-
-	for _, alias := range aliasesLi {
+	// make parser available under aliases also
+	for _, alias := range aliases {
 		p.NameParserMap[alias] = parser
+		p.Choices = append(p.Choices, alias)
 	}
 
 	if deprecated {
-		// p.Deprecated = append(p.Deprecated, name)
-		fmt.Printf("WARNING on deprecated")
+		p.Deprecated[name] = struct{}{}
+		for _, alias := range aliases {
+			p.Deprecated[alias] = struct{}{}
+		}
 	}
 
-	return nil, nil
+	return parser, nil
 }
 
-func (p *SubParsersAction) GetSubActions() []ActionInterface {
+func (p *SubParsersAction) GetSubActions_() []ActionInterface {
 	return p.ChoicesActions
 }
 
-func (p *SubParsersAction) Call(parser *ArgumentParser, namespace Namespace, values []any, optionString string) error {
-	parserName := values[0].(string)
-	argStrings := values[1:]
+func (p *SubParsersAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	parserName := fmt.Sprintf("%v", values.([]any)[0])
+	argStrings := []string{}
+	for _, value := range values.([]any)[1:] {
+		argStrings = append(argStrings, fmt.Sprintf("%v", value))
+	}
 
 	// set the parser name if requested
 	if p.Dest != SUPPRESS {
 		namespace.Set(p.Dest, parserName)
 	}
 
+	// plugins run once the parent parser has parsed its arguments
+	if path, exist := p.Plugins[parserName]; exist {
+		namespace.plugin = &pluginCommand{Name: parserName, Path: path, Args: argStrings, Parser: parser}
+		return nil
+	}
+
 	// select the parser
-	// subparser, exist := p.NameParserMap[parserName]
-	// if !exist {
-	// 	keys := make([]string, len(p.NameParserMap))
-	// 	joinedChoices := strings.Join(keys, ", ")
-	// 	panic(fmt.Sprintf("unknown parser %s (choices: %s)", joinedChoices, parserName))
-	// }
-
-	for depr := range p.Deprecated {
-		if depr == parserName {
-			// parser.Warning(fmt.Sprintf("command '%(parser_name)s' is deprecated", parserName))
-			fmt.Printf("command %s is deprecated\n", parserName)
+	subparser, exist := p.NameParserMap[parserName]
+	if !exist {
+		choices := []string{}
+		for _, choice := range p.Choices {
+			choices = append(choices, fmt.Sprintf("%v", choice))
 		}
+		msg := fmt.Sprintf("unknown parser '%s' (choices: %s)", parserName, strings.Join(choices, ", "))
+		return NewArgumentError(p.Action, msg)
+	}
+
+	if _, deprecated := p.Deprecated[parserName]; deprecated {
+		parser.Warning(fmt.Sprintf("command '%s' is deprecated", parserName))
 	}
 
 	// parse all the remaining options into the namespace
@@ -161,24 +191,31 @@ func (p *SubParsersAction) Call(parser *ArgumentParser, namespace Namespace, val
 	// In case this subparser defines new defaults, we parse them
 	// in a new namespace object and then update the original
 	// namespace for the relevant parts.
+	subnamespace := NewNamespace(nil)
+	subnamespace.argIndex = namespace.argIndex + 1
+	subparser.parent = parser
+	defer func() { subparser.parent = nil }()
+	subnamespace, argStrings, err := subparser.ParseKnownArgs(argStrings, subnamespace)
+	if err != nil {
+		return err
+	}
+	for key, value := range subnamespace.attributes {
+		namespace.Set(key, value)
+	}
+	for key, source := range subnamespace.sources {
+		namespace.setSource(key, source)
+	}
+	for key := range subnamespace.sensitive {
+		namespace.MarkSensitive(key)
+	}
+	namespace.printConfig = namespace.printConfig || subnamespace.printConfig
 
-	// subnamespace, argStrings := subparser.parseKnownArgs(argStrings, nil)
-	// for key, value := range subnamespace {
-	// 	namespace.Set(key, value)
-	// }
-
-	if argStrings != nil {
-		if unrec, exist := namespace.Get(UNRECOGNIZED_ARGS_ATTR); !exist {
-			namespace.Set(UNRECOGNIZED_ARGS_ATTR, make(map[string]any))
-		} else {
-			switch t := unrec.(type) {
-			case []any:
-				newUnrec := append(t, argStrings)
-				namespace.Set(UNRECOGNIZED_ARGS_ATTR, newUnrec)
-			default:
-				fmt.Printf("Wrong unrec type %v\n", t)
-			}
+	if len(argStrings) > 0 {
+		unrecognized, exist := namespace.Get(UNRECOGNIZED_ARGS_ATTR)
+		if !exist {
+			unrecognized = []string{}
 		}
+		namespace.Set(UNRECOGNIZED_ARGS_ATTR, append(unrecognized.([]string), argStrings...))
 	}
 	return nil
 }
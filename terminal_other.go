@@ -0,0 +1,25 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package argparse
+
+import (
+	"errors"
+	"os"
+)
+
+// isTerminal reports whether file is a character device, the closest
+// approximation of a terminal on this system.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// setEcho is not supported on this system, the answers are echoed.
+func setEcho(file *os.File, echo bool) error {
+	return errors.New("turning the echo off is not supported")
+}
+
+// makeRaw is not supported on this system, lines are read without editing.
+func makeRaw(file *os.File) (func(), error) {
+	return nil, errors.New("raw mode is not supported")
+}
@@ -0,0 +1,72 @@
+package argparse_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+// upperAction stores its argument in upper case.
+type upperAction struct {
+	*argparse.Action
+}
+
+func (a *upperAction) Call(parser *argparse.ArgumentParser, namespace *argparse.Namespace, values any, optionString string) error {
+	namespace.Set(a.Dest, strings.ToUpper(fmt.Sprintf("%v", values)))
+	return nil
+}
+
+func TestRegisterAction(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.RegisterAction("upper", func(argument *argparse.Argument) argparse.ActionInterface {
+		return &upperAction{Action: argparse.NewAction(argument)}
+	})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--name"}, Action: "upper", Help: "the name"})
+
+	// the actions of the groups are registered in the parser
+	group := parser.AddArgumentGroup(argparse.NewArgumentGroup(parser.ActionsContainer, "group", "", "", nil, nil))
+	group.AddArgument(&argparse.Argument{OptionStrings: []string{"code"}, Action: "upper"})
+
+	ns, err := parser.ParseArgs([]string{"--name", "alice", "x1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"name": "ALICE", "code": "X1"})
+	if help := parser.FormatHelp(); !strings.Contains(help, "--name NAME  the name") {
+		t.Errorf("unexpected help:\n%s", help)
+	}
+}
+
+func TestAddArgumentPanics(t *testing.T) {
+	tests := []struct {
+		argument *argparse.Argument
+		message  string
+	}{
+		{&argparse.Argument{OptionStrings: []string{"--x"}, Action: "missing"}, `unknown action "missing"`},
+		{&argparse.Argument{OptionStrings: []string{"--x"}, Nargs: "?!"}, "invalid nargs value: '?!'"},
+		{&argparse.Argument{OptionStrings: []string{"--x"}, Nargs: 2, Const: 1}, "nargs must be '?' to supply const"},
+		{&argparse.Argument{OptionStrings: []string{"--x"}, Action: "custom", Nargs: -1}, "invalid nargs value: -1"},
+	}
+	for _, test := range tests {
+		parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser.RegisterAction("custom", func(argument *argparse.Argument) argparse.ActionInterface {
+			return &upperAction{Action: argparse.NewAction(argument)}
+		})
+		func() {
+			defer func() {
+				if r := recover(); fmt.Sprint(r) != test.message {
+					t.Errorf("expected panic %q, got %v", test.message, r)
+				}
+			}()
+			parser.AddArgument(test.argument)
+		}()
+	}
+}
@@ -0,0 +1,59 @@
+package argparse_test
+
+import (
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestArgumentGroups(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	parent, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "addHelp": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.AddArgument(&argparse.Argument{OptionStrings: []string{"--debug"}, Action: "store_true"})
+	parentGroup := parent.AddArgumentGroup(argparse.NewArgumentGroup(parent.ActionsContainer, "logging", "", "", nil, nil))
+	parentGroup.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-file"}})
+	parent.SetDefaults(map[string]any{"mode": "parent"})
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "parents": []*argparse.ArgumentParser{parent}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := parser.AddArgumentGroup(argparse.NewArgumentGroup(parser.ActionsContainer, "output", "where to write", "", nil, nil))
+	mutex := group.AddMutuallyExclusiveGroup(argparse.NewMutuallyExclusiveGroup("", "", nil, nil))
+	mutex.Struct().Required = true
+	mutex.AddArgument(&argparse.Argument{OptionStrings: []string{"--json"}, Action: "store_true"})
+	mutex.AddArgument(&argparse.Argument{OptionStrings: []string{"--xml"}, Action: "store_true"})
+
+	expected := `usage: mytool [-h] [--debug] [--log-file LOG_FILE] (--json | --xml)
+
+options:
+  -h, --help           show this help message and exit
+  --debug
+
+logging:
+  --log-file LOG_FILE
+
+output:
+  where to write
+
+  --json
+  --xml
+`
+	if help := parser.FormatHelp(); help != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, help)
+	}
+
+	ns, err := parser.ParseArgs([]string{"--debug", "--xml"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"debug": true, "xml": true, "json": false, "mode": "parent", "log_file": nil})
+
+	checkErrors(t, parser, map[string]string{
+		"--json --xml": "argument --xml: not allowed with argument --json",
+		"--debug":      "the following arguments are required: (--json | --xml)",
+	})
+}
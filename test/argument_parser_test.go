@@ -0,0 +1,77 @@
+package argparse_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestArgumentParser(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"prog": "mytool", "exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v", "--verbose"}, Action: "store_true"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--level"}, Type: "int", Default: 1})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"source"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"targets"}, Nargs: argparse.ZERO_OR_MORE})
+
+	namespace, err := parser.ParseArgs([]string{"src", "--level=3", "-v", "a", "b", "--tag", "x", "--tag", "y"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"verbose": true,
+		"level":   3,
+		"tag":     []any{"x", "y"},
+		"source":  "src",
+		"targets": []any{"a", "b"},
+	}
+	for dest, value := range expected {
+		if got, _ := namespace.Get(dest); !reflect.DeepEqual(got, value) {
+			t.Errorf("expected %s %#v, got %#v", dest, value, got)
+		}
+	}
+
+	if _, err := parser.ParseArgs([]string{"src", "--level", "abc"}, nil); err == nil || err.Error() != "argument --level: invalid int value: 'abc'" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := parser.ParseArgs([]string{"src", "--unknown"}, nil); err == nil || err.Error() != "unrecognized arguments: --unknown" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, extras, err := parser.ParseKnownArgs([]string{"src", "--unknown"}, nil); err != nil || !reflect.DeepEqual(extras, []string{"--unknown"}) {
+		t.Errorf("unexpected extras %q, error %v", extras, err)
+	}
+}
+
+func TestArgumentParserSubparsers(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"prog": "mytool", "exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"--jobs"}, Type: "int"})
+
+	namespace, err := parser.ParseArgs([]string{"build", "--jobs", "4"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if command, _ := namespace.Get("command"); command != "build" {
+		t.Errorf("expected command build, got %v", command)
+	}
+	if jobs, _ := namespace.Get("jobs"); jobs != 4 {
+		t.Errorf("expected jobs 4, got %v", jobs)
+	}
+	if _, err := parser.ParseArgs([]string{"test"}, nil); err == nil {
+		t.Errorf("expected an error for an unknown command")
+	}
+}
@@ -2,6 +2,7 @@ package argparse_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/goimp/argparse"
@@ -38,3 +39,77 @@ func TestBooleanAction(t *testing.T) {
 		fmt.Printf("Format usage: %s\n", f)
 	}
 }
+
+func TestBooleanOptionalParsing(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "addHelp": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--color"}, Action: "boolean_optional", Default: true, Help: "colorize the output"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--cache"}, Action: "boolean_optional", NegationPrefix: "disable-"})
+
+	tests := []struct {
+		args     string
+		expected map[string]any
+	}{
+		{"", map[string]any{"color": true, "cache": nil}},
+		{"--no-color --cache", map[string]any{"color": false, "cache": true}},
+		{"--disable-cache --color", map[string]any{"color": true, "cache": false}},
+	}
+	for _, test := range tests {
+		ns, err := parser.ParseArgs(strings.Fields(test.args), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.args, err)
+		}
+		checkNamespace(t, ns, test.expected)
+	}
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{"[--color | --no-color]", "[--cache | --disable-cache]", "colorize the output (default: True)"} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+
+	// the negative options use the prefix characters of the parser
+	parser, err = argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prefixChars": "+"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"++color"}, Action: "boolean_optional"})
+	if ns, err := parser.ParseArgs([]string{"++no-color"}, nil); err != nil {
+		t.Error(err)
+	} else {
+		checkNamespace(t, ns, map[string]any{"color": false})
+	}
+}
+
+func TestBooleanOptionalSources(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--color"}, Action: "boolean_optional", Default: true})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--cache"}, Action: "boolean_optional"})
+
+	t.Setenv("MYTOOL_COLOR", "no")
+	config := writeConfig(t, "app.json", `{"cache": true}`)
+	ns, err := parser.ParseArgs([]string{"--config", config}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"color": false, "cache": true})
+
+	// the command line wins
+	ns, err = parser.ParseArgs([]string{"--config", config, "--color", "--no-cache"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"color": true, "cache": false})
+
+	t.Setenv("MYTOOL_COLOR", "maybe")
+	checkErrors(t, parser, map[string]string{
+		"": "argument --color/--no-color: invalid boolean value: 'maybe' (from environment variable MYTOOL_COLOR)",
+	})
+}
@@ -0,0 +1,154 @@
+package argparse_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestCompletion(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"-v", "--verbose"},
+		Action:        "count",
+		Help:          "increase verbosity",
+	})
+	parser.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"--color"},
+		Choices:       []any{"auto", "never"},
+		Help:          "colorize output",
+	})
+	parser.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"--secret"},
+		Help:          argparse.SUPPRESS,
+	})
+	parser.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"--old"},
+		Deprecated:    true,
+	})
+
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{
+		"help":    "build the project",
+		"aliases": []string{"b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"--output"},
+		Type:          argparse.NewFileType("w", -1, "", ""),
+		Help:          "output file",
+	})
+	build.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"target"},
+		Choices:       []any{"debug", "release"},
+	})
+	if _, err := subparsers.AddParser("legacy", true, map[string]any{"help": "old command"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		argparse.BASH: {
+			"complete -F _mytool_completion mytool",
+			"'mytool build'|'mytool b') cmd='mytool build' ;;",
+			"COMPREPLY=($(compgen -W 'auto never' -- \"${cur}\"))",
+			"COMPREPLY=($(compgen -f -- \"${cur}\"))",
+			"COMPREPLY=($(compgen -W 'build b' -- \"${cur}\"))",
+		},
+		argparse.ZSH: {
+			"#compdef mytool",
+			"'*'{-v,--verbose}'[increase verbosity]'",
+			"--color'[colorize output]:color:(auto never)'",
+			"'build:build the project'",
+			"--output'[output file]:output:_files'",
+			"':target:(debug release)'",
+			"build|b) _mytool_build ;;",
+		},
+		argparse.FISH: {
+			"complete -c 'mytool' -n \"__fish_mytool_using_command 'mytool'\" -s 'v' -l 'verbose' -d 'increase verbosity'",
+			"-l 'color' -r -f -a 'auto never' -d 'colorize output'",
+			"-f -a 'build' -d 'build the project'",
+			"-l 'output' -r -F -d 'output file'",
+		},
+		argparse.POWERSHELL: {
+			"Register-ArgumentCompleter -Native -CommandName 'mytool'",
+			"'mytool b' { $command = 'mytool build' }",
+			"'--color' { $values = @('auto', 'never') }",
+			"'--output' { $values = @() }",
+			"[System.Management.Automation.CompletionResult]::new('build', 'build', 'ParameterValue', 'build the project')",
+		},
+	}
+
+	for shell, fragments := range expected {
+		script, err := parser.FormatCompletion(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, fragment := range fragments {
+			if !strings.Contains(script, fragment) {
+				t.Errorf("%s completion does not contain %q:\n%s", shell, fragment, script)
+			}
+		}
+		for _, hidden := range []string{"--secret", "--old", "legacy"} {
+			if strings.Contains(script, hidden) {
+				t.Errorf("%s completion offers hidden %q", shell, hidden)
+			}
+		}
+	}
+
+	if _, err := parser.FormatCompletion("tcsh"); err == nil {
+		t.Errorf("expected an error for an unsupported shell")
+	}
+
+	// the bash script completes the words
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not available")
+	}
+	script, err := parser.FormatCompletion(argparse.BASH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "mytool.bash")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	complete := func(words ...string) string {
+		command := fmt.Sprintf(
+			`source %s; COMP_WORDS=(%s); COMP_CWORD=%d; _mytool_completion; echo "${COMPREPLY[*]}"`,
+			path, strings.Join(words, " "), len(words)-1,
+		)
+		output, err := exec.Command(bash, "-c", command).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %s", err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	if got := complete("mytool", "--co"); got != "--color" {
+		t.Errorf("expected %q, got %q", "--color", got)
+	}
+	if got := complete("mytool", "--color", "n"); got != "never" {
+		t.Errorf("expected %q, got %q", "never", got)
+	}
+	if got := complete("mytool", "b"); got != "build b" {
+		t.Errorf("expected %q, got %q", "build b", got)
+	}
+	if got := complete("mytool", "-v", "b", "r"); got != "release" {
+		t.Errorf("expected %q, got %q", "release", got)
+	}
+}
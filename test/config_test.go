@@ -0,0 +1,231 @@
+package argparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestConfigFormats(t *testing.T) {
+	expected := map[string]any{
+		"port":      8080,
+		"host":      "example.com",
+		"log_level": "debug",
+		"tag":       []any{"a", "b"},
+		"dry_run":   true,
+		"ratio":     0.5,
+		"user":      "admin",
+	}
+	configs := map[string]string{
+		"config.json": `{
+  "port": 8080,
+  "host": "example.com",
+  "log-level": "debug",
+  "tag": ["a", "b"],
+  "dry_run": true,
+  "ratio": 0.5,
+  "user": "admin"
+}`,
+		"config.toml": `# settings
+port = 8_080
+host = "example.com" # the host
+log-level = 'debug'
+tag = [
+  "a",
+  "b",
+]
+dry_run = true
+ratio = 0.5
+user = "admin"
+`,
+		"config.yaml": `---
+port: 8080
+host: example.com  # the host
+log-level: "debug"
+tag:
+- a
+- b
+dry_run: yes
+ratio: 0.5
+user: admin
+`,
+		"config.ini": `; settings
+port = 8080
+host: example.com
+log-level = "debug"
+tag = a,
+  b
+dry_run = on
+ratio = 0.5
+user = admin
+`,
+	}
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--host"}, Default: "localhost"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--ratio"}, Type: "float"})
+	parser.SetDefaults(map[string]any{"user": "nobody"})
+
+	for name, content := range configs {
+		path := writeConfig(t, name, content)
+		ns, err := parser.ParseArgs([]string{"--config", path}, nil)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		checkNamespace(t, ns, expected)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	path := writeConfig(t, "config.json", `{"port": 8080, "host": "example.com", "log_level": "info"}`)
+	t.Setenv("MYTOOL_HOST", "env.example.com")
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--host"}, Default: "localhost"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.SetDefaults(map[string]any{"user": "nobody"})
+
+	// command line > environment > configuration file > defaults
+	ns, err := parser.ParseArgs([]string{"--config=" + path, "--log-level", "debug"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{
+		"log_level": "debug",
+		"host":      "env.example.com",
+		"port":      8080,
+		"user":      "nobody",
+		"config":    []any{path},
+	})
+
+	// later files win
+	other := writeConfig(t, "other.toml", "port = 9090\n")
+	ns, err = parser.ParseArgs([]string{"--config", path, "--config", other}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"port": 9090, "log_level": "info"})
+}
+
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "mytool.yaml")
+	if err := os.WriteFile(present, []byte("port: 1234\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--host"}, Default: "localhost"})
+	parser.ConfigFiles = []string{filepath.Join(dir, "missing.json"), present}
+	ns, err := parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"port": 1234})
+
+	// a config option replaces the default files
+	path := writeConfig(t, "config.json", `{"host": "example.com"}`)
+	ns, err = parser.ParseArgs([]string{"--config", path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"port": 80, "host": "example.com"})
+}
+
+func TestConfigSubcommands(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Required: true})
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"--jobs"}, Type: "int", Default: 1})
+
+	path := writeConfig(t, "config.toml", `port = 8080
+
+[build]
+output = "out"
+jobs = 4
+`)
+	ns, err := parser.ParseArgs([]string{"--config", path, "build", "--jobs", "2"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"port": 8080, "command": "build", "output": "out", "jobs": 2})
+
+	// a section for an unknown subcommand is an error
+	path = writeConfig(t, "config.yaml", "deploy:\n  target: prod\n")
+	_, err = parser.ParseArgs([]string{"--config", path, "build"}, nil)
+	if err == nil || err.Error() != path+":2: unknown key 'deploy.target'" {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--host"}, Default: "localhost"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"unknown.json", "{\n  \"port\": 1,\n  \"colour\": \"red\"\n}", ":3: unknown key 'colour'"},
+		{"unknown.ini", "[server]\nport = 1\n", ":2: unknown key 'server.port'"},
+		{"type.toml", "port = \"http\"\n", "argument --port: invalid int value: 'http' (from "},
+		{"choice.yaml", "\nlog-level: trace\n", "argument --log-level: invalid choice: 'trace' (choose from debug, info) (from "},
+		{"bool.json", `{"dry_run": "maybe"}`, "argument --dry-run: invalid boolean value: 'maybe' (from "},
+		{"syntax.toml", "port = 1\nhost = \n", ":2: missing value"},
+		{"syntax.json", "{\n  \"port\": 1,\n  \"host\" \"x\"\n}", ":3: invalid character"},
+		{"syntax.yaml", "port: 1\n  host: x\n", ":2: unexpected indentation"},
+		{"config.xml", "<port>1</port>", ": unsupported configuration file format"},
+	}
+	for _, test := range tests {
+		path := writeConfig(t, test.name, test.content)
+		_, err := parser.ParseArgs([]string{"--config", path}, nil)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+
+	if _, err := parser.ParseArgs([]string{"--config", "missing.json"}, nil); err == nil || !strings.HasPrefix(err.Error(), "missing.json: can't open") {
+		t.Errorf("expected can't open error, got %v", err)
+	}
+}
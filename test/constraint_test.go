@@ -0,0 +1,72 @@
+package argparse_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestConstraints(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--cert"}, Help: "certificate file"})
+	key := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--key"}})
+	dryRun := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+	force := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--force"}, Action: "store_true"})
+	format := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--format"}, Choices: []any{"text", "file"}, Default: "text"})
+	output := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}})
+	id := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--id"}, Env: "MYTOOL_ID"})
+	name := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--name"}})
+	user := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--user"}})
+	password := parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--password"}})
+
+	parser.Requires(cert, key)
+	parser.Conflicts(dryRun, force)
+	parser.RequiredIf(output, format, "file")
+	parser.AtLeastOne(id, name)
+	parser.AllOrNone(user, password)
+
+	for _, args := range []string{
+		"--id 1",
+		"--name x --cert c --key k",
+		"--id 1 --dry-run --format file --output out",
+		"--id 1 --user u --password p --force",
+	} {
+		if _, err := parser.ParseArgs(strings.Fields(args), nil); err != nil {
+			t.Errorf("%s: unexpected error %v", args, err)
+		}
+	}
+
+	checkErrors(t, parser, map[string]string{
+		"--id 1 --cert c":          "argument --cert: requires argument --key",
+		"--id 1 --dry-run --force": "argument --dry-run: not allowed with argument --force",
+		"--id 1 --format file":     "argument --output: required when --format is file",
+		"--cert c --key k":         "at least one of the arguments --id --name is required",
+		"--id 1 --password p":      "the arguments --user --password must be given together, missing: --user",
+	})
+
+	// the arguments from the environment are present
+	t.Setenv("MYTOOL_ID", "1")
+	if _, err := parser.ParseArgs([]string{}, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{
+		"  --cert CERT           certificate file [requires --key]\n",
+		"  --dry-run             [conflicts with --force]\n",
+		"  --force               [conflicts with --dry-run]\n",
+		"  --output OUTPUT       [required if --format is file]\n",
+		"  --id ID               [env var: MYTOOL_ID] [at least one of --id, --name]\n",
+		"  --password PASSWORD   [all or none of --user, --password]\n",
+		"  --key KEY\n",
+	} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+}
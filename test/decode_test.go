@@ -0,0 +1,126 @@
+package argparse_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goimp/argparse"
+)
+
+type Common struct {
+	Verbose int
+}
+
+type Config struct {
+	Common
+	Port     uint16
+	Host     string
+	LogLevel *string
+	Ratio    *float64
+	Tags     []string
+	Sizes    []int8
+	Timeout  time.Duration
+	Address  netip.Addr
+	Output   string `argparse:"out"`
+	Ignored  string `argparse:"-"`
+	DryRun   bool
+	Extra    any
+	hidden   string
+}
+
+func TestNamespaceDecode(t *testing.T) {
+	ns := argparse.NewNamespace(map[string]any{
+		"verbose":   2,
+		"port":      8080,
+		"host":      "localhost",
+		"log_level": nil,
+		"ratio":     1,
+		"tags":      []any{"a", "b"},
+		"sizes":     []any{1, int64(-2)},
+		"timeout":   "1m30s",
+		"address":   "127.0.0.1",
+		"out":       "build",
+		"ignored":   "x",
+		"dry-run":   true,
+		"extra":     []any{1, "two"},
+		"hidden":    "x",
+		"command":   "build",
+	})
+
+	level := "info"
+	config := Config{LogLevel: &level, Ignored: "kept"}
+	if err := ns.Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	ratio := 1.0
+	expected := Config{
+		Common:   Common{Verbose: 2},
+		Port:     8080,
+		Host:     "localhost",
+		LogLevel: nil,
+		Ratio:    &ratio,
+		Tags:     []string{"a", "b"},
+		Sizes:    []int8{1, -2},
+		Timeout:  90 * time.Second,
+		Address:  netip.MustParseAddr("127.0.0.1"),
+		Output:   "build",
+		Ignored:  "kept",
+		DryRun:   true,
+		Extra:    []any{1, "two"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected %+v, got %+v", expected, config)
+	}
+}
+
+func TestNamespaceDecodeErrors(t *testing.T) {
+	tests := []struct {
+		attributes map[string]any
+		message    string
+	}{
+		{map[string]any{"port": "http"}, `cannot decode 'port' into Config.Port: string value "http" is not compatible with uint16`},
+		{map[string]any{"port": 70000}, `cannot decode 'port' into Config.Port: value 70000 overflows uint16`},
+		{map[string]any{"port": -1}, `cannot decode 'port' into Config.Port: negative value -1 for uint16`},
+		{map[string]any{"sizes": []any{1, 300}}, `cannot decode 'sizes' into Config.Sizes[1]: value 300 overflows int8`},
+		{map[string]any{"tags": "a"}, `cannot decode 'tags' into Config.Tags: string value "a" is not compatible with []string`},
+		{map[string]any{"timeout": "soon"}, `cannot decode 'timeout' into Config.Timeout: time: invalid duration "soon"`},
+		{map[string]any{"verbose": 1.5}, `cannot decode 'verbose' into Config.Verbose: float64 value 1.5 is not compatible with int`},
+	}
+	for _, test := range tests {
+		var config Config
+		err := argparse.NewNamespace(test.attributes).Decode(&config)
+		if err == nil || err.Error() != test.message {
+			t.Errorf("expected error %q, got %v", test.message, err)
+		}
+	}
+
+	var config Config
+	if err := argparse.NewNamespace(nil).Decode(config); err == nil {
+		t.Errorf("expected an error for a non-pointer target")
+	}
+}
+
+func TestParseArgsDecode(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v", "--verbose"}, Action: "count"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append", Dest: "tags"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}})
+
+	ns, err := parser.ParseArgs([]string{"-vv", "--tag", "a", "--tag", "b"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	if err := ns.Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Verbose != 2 || config.Port != 80 || !reflect.DeepEqual(config.Tags, []string{"a", "b"}) || config.LogLevel != nil {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
@@ -0,0 +1,245 @@
+package argparse_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goimp/argparse"
+)
+
+func candidateValues(candidates []argparse.Candidate) []string {
+	values := []string{}
+	for _, candidate := range candidates {
+		values = append(values, candidate.Value)
+	}
+	return values
+}
+
+func TestDynamicCompletion(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v", "--verbose"}, Action: "count"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--color"}, Choices: []any{"auto", "never"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--secret"}, Help: argparse.SUPPRESS})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--old"}, Deprecated: true})
+	parser.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"--cluster"},
+		Help:          "target cluster",
+		Completer: func(prefix string, ns *argparse.Namespace) []argparse.Candidate {
+			return []argparse.Candidate{
+				{Value: "prod-eu", Description: "production"},
+				{Value: "prod-us", Description: "production"},
+				{Value: "staging", Description: "staging"},
+			}
+		},
+	})
+
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{"help": "build the project", "aliases": []string{"b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Type: argparse.NewFileType("w", -1, "", "")})
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"target"}, Choices: []any{"debug", "release"}})
+	if _, err := subparsers.AddParser("legacy", true, map[string]any{"help": "old command"}); err != nil {
+		t.Fatal(err)
+	}
+	deploy, err := subparsers.AddParser("deploy", false, map[string]any{"help": "deploy a branch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy.AddArgument(&argparse.Argument{
+		OptionStrings: []string{"branch"},
+		Completer: func(prefix string, ns *argparse.Namespace) []argparse.Candidate {
+			// the completer sees the options parsed so far
+			cluster, _ := ns.Get("cluster")
+			if cluster == "staging" {
+				return []argparse.Candidate{{Value: "feature-x"}, {Value: "main"}}
+			}
+			return []argparse.Candidate{{Value: "main"}}
+		},
+	})
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"--cl"}, []string{"--cluster"}},
+		{[]string{"--cluster", "prod"}, []string{"prod-eu", "prod-us"}},
+		{[]string{"--cluster=st"}, []string{"--cluster=staging"}},
+		{[]string{"--color", ""}, []string{"auto", "never"}},
+		{[]string{"-v", ""}, []string{"build", "b", "deploy"}},
+		{[]string{"b", "--"}, []string{"--help", "--output"}},
+		{[]string{"b", "r"}, []string{"release"}},
+		{[]string{"--cluster", "staging", "deploy", ""}, []string{"feature-x", "main"}},
+		{[]string{"--cluster", "prod-eu", "deploy", ""}, []string{"main"}},
+		{[]string{"build", "release", ""}, []string{"-h", "--help", "--output"}},
+		{[]string{"unknown", ""}, []string{}},
+	}
+	for _, test := range tests {
+		got := candidateValues(parser.Complete(test.args))
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Complete(%q): expected %q, got %q", test.args, test.expected, got)
+		}
+	}
+
+	// hidden options and deprecated subcommands are not offered
+	for _, candidate := range parser.Complete([]string{"-"}) {
+		if candidate.Value == "--secret" || candidate.Value == "--old" {
+			t.Errorf("unexpected hidden option %q", candidate.Value)
+		}
+	}
+	for _, candidate := range parser.Complete([]string{"l"}) {
+		t.Errorf("unexpected deprecated subcommand %q", candidate.Value)
+	}
+
+	// the completers do not change the parsing
+	ns, err := parser.ParseArgs([]string{"-vv", "--cluster", "staging", "b", "--output", os.DevNull, "debug"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for dest, expected := range map[string]any{
+		"verbose": 2,
+		"cluster": "staging",
+		"command": "b",
+		"target":  "debug",
+		"color":   nil,
+	} {
+		if got, _ := ns.Get(dest); got != expected {
+			t.Errorf("%s: expected %v, got %v", dest, expected, got)
+		}
+	}
+	if output, _ := ns.Get("output"); output == nil {
+		t.Errorf("expected an opened output file")
+	} else {
+		output.(*os.File).Close()
+	}
+
+	_, extras, err := parser.ParseKnownArgs([]string{"--cluster=prod-eu", "deploy", "main", "--unknown"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(extras, []string{"--unknown"}) {
+		t.Errorf("expected unrecognized [--unknown], got %q", extras)
+	}
+
+	if _, _, err := parser.ParseKnownArgs([]string{"--color", "always", "build", "debug"}, nil); err == nil ||
+		err.Error() != "argument --color: invalid choice: 'always' (choose from auto, never)" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDynamicCompletionFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"out.txt", "other.txt", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "outdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Type: argparse.NewFileType("w", -1, "", "")})
+	got := candidateValues(parser.Complete([]string{"--output", filepath.Join(dir, "out")}))
+	expected := []string{filepath.Join(dir, "out.txt"), filepath.Join(dir, "outdir") + string(filepath.Separator)}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestCompleteWithoutSideEffects(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "keep.txt")
+	if err := os.WriteFile(output, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the FileType of --output is not opened and secret_stdin does not read
+	// stdin, which never ends
+	stdin, writer := io.Pipe()
+	defer writer.Close()
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "stdin": stdin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Type: argparse.NewFileType("w", -1, "", "")})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token-stdin"}, Dest: "token", Action: "secret_stdin"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"target"}, Choices: []any{"debug", "release"}})
+
+	done := make(chan []string)
+	go func() {
+		done <- candidateValues(parser.Complete([]string{"--token-stdin", "--output", output, "r"}))
+	}()
+	select {
+	case got := <-done:
+		if !reflect.DeepEqual(got, []string{"release"}) {
+			t.Errorf("expected [release], got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Complete blocked reading stdin")
+	}
+
+	if content, err := os.ReadFile(output); err != nil || string(content) != "keep" {
+		t.Errorf("expected %s to be kept, got %q (%v)", output, content, err)
+	}
+}
+
+func TestFormatCandidates(t *testing.T) {
+	candidates := []argparse.Candidate{
+		{Value: "prod:eu", Description: "production\ncluster"},
+		{Value: "staging"},
+	}
+
+	expected := map[string]string{
+		argparse.BASH:       "prod:eu\nstaging\n",
+		argparse.ZSH:        "prod\\:eu:production cluster\nstaging\n",
+		argparse.FISH:       "prod:eu\tproduction cluster\nstaging\n",
+		argparse.POWERSHELL: "prod:eu\tproduction cluster\nstaging\n",
+	}
+	for shell, output := range expected {
+		if got := argparse.FormatCandidates(candidates, shell); got != output {
+			t.Errorf("%s: expected %q, got %q", shell, output, got)
+		}
+	}
+}
+
+func TestDynamicCompletionScript(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		argparse.BASH:       "COMPREPLY=($(ARGPARSE_COMPLETE=bash \"${COMP_WORDS[0]}\" \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null))",
+		argparse.ZSH:        "compdef _mytool_dynamic_completion mytool",
+		argparse.FISH:       "complete -c 'mytool' -f -a '(__fish_mytool_dynamic_completion)'",
+		argparse.POWERSHELL: "$env:ARGPARSE_COMPLETE = 'powershell'",
+	}
+	for shell, fragment := range expected {
+		script, err := parser.FormatDynamicCompletion(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(script, fragment) {
+			t.Errorf("%s dynamic completion does not contain %q:\n%s", shell, fragment, script)
+		}
+	}
+
+	if _, err := parser.FormatDynamicCompletion("tcsh"); err == nil {
+		t.Errorf("expected an error for an unsupported shell")
+	}
+}
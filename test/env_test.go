@@ -0,0 +1,123 @@
+package argparse_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestEnvFallback(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80, Env: "PORT"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--pair"}, Nargs: 2})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v"}, Action: "count", Dest: "verbose"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token"}, Required: true, Env: argparse.SUPPRESS})
+
+	t.Setenv("PORT", "8080")
+	t.Setenv("MYTOOL_LOG_LEVEL", "debug")
+	t.Setenv("MYTOOL_TAG", "a, b,,c")
+	t.Setenv("MYTOOL_PAIR", "x,y")
+	t.Setenv("MYTOOL_DRY_RUN", "yes")
+	t.Setenv("MYTOOL_VERBOSE", "3")
+	t.Setenv("MYTOOL_TOKEN", "ignored")
+
+	ns, err := parser.ParseArgs([]string{"--token", "t", "--log-level", "info"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"port":      8080,
+		"log_level": "info",
+		"tag":       []any{"a", "b", "c"},
+		"pair":      []any{"x", "y"},
+		"dry_run":   true,
+		"verbose":   3,
+		"token":     "t",
+	}
+	for dest, value := range expected {
+		if got, _ := ns.Get(dest); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s: expected %#v, got %#v", dest, value, got)
+		}
+	}
+
+	// the environment satisfies required arguments unless disabled
+	if _, err := parser.ParseArgs([]string{}, nil); err == nil || !strings.Contains(err.Error(), "--token") {
+		t.Errorf("expected --token to be required, got %v", err)
+	}
+}
+
+func TestEnvFallbackErrors(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Env: "PORT"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--pair"}, Nargs: 2})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"PORT", "http", "argument --port: invalid int value: 'http' (from environment variable PORT)"},
+		{"MYTOOL_LOG_LEVEL", "trace", "argument --log-level: invalid choice: 'trace' (choose from debug, info) (from environment variable MYTOOL_LOG_LEVEL)"},
+		{"MYTOOL_PAIR", "x", "argument --pair: expected 2 arguments (from environment variable MYTOOL_PAIR)"},
+		{"MYTOOL_DRY_RUN", "maybe", "argument --dry-run: invalid boolean value: 'maybe' (from environment variable MYTOOL_DRY_RUN)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(test.name, test.value)
+			_, err := parser.ParseArgs([]string{}, nil)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+
+	// false booleans leave the default
+	t.Setenv("MYTOOL_DRY_RUN", "off")
+	ns, err := parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dryRun, _ := ns.Get("dry_run"); dryRun != false {
+		t.Errorf("expected dry_run false, got %v", dryRun)
+	}
+}
+
+func TestEnvHelp(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Env: "PORT", Help: "listen port"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v"}, Action: "count", Dest: "verbose"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token"}, Env: argparse.SUPPRESS})
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{
+		"--port PORT           listen port [env var: PORT]\n",
+		"--log-level {debug,info}\n                        [env var: MYTOOL_LOG_LEVEL]\n",
+		"-v                    [env var: MYTOOL_VERBOSE]\n",
+		"--token TOKEN\n",
+	} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+	if strings.Contains(help, "MYTOOL_HELP") {
+		t.Errorf("help option has an environment variable:\n%s", help)
+	}
+}
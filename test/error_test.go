@@ -0,0 +1,68 @@
+package argparse_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestRequiredArguments(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"source"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"pair"}, Nargs: 2})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"rest"}, Nargs: argparse.ZERO_OR_MORE})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Required: true})
+	mutex := parser.AddMutuallyExclusiveGroup(argparse.NewMutuallyExclusiveGroup("", "", nil, nil))
+	mutex.Struct().Required = true
+	mutex.AddArgument(&argparse.Argument{OptionStrings: []string{"--json"}, Action: "store_true"})
+	mutex.AddArgument(&argparse.Argument{OptionStrings: []string{"--xml"}, Action: "store_true"})
+
+	// the required groups are reported with the arguments
+	checkErrors(t, parser, map[string]string{
+		"":                 "the following arguments are required: source, pair, --output, (--json | --xml)",
+		"a":                "the following arguments are required: pair, --output, (--json | --xml)",
+		"a b c --json":     "the following arguments are required: --output",
+		"a b c --output o": "the following arguments are required: (--json | --xml)",
+	})
+	if _, err := parser.ParseArgs([]string{"a", "b", "c", "--output", "o", "--xml"}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestError(t *testing.T) {
+	// the parser exits in a child process running this test
+	if os.Getenv("ARGPARSE_TEST_ERROR") == "1" {
+		parser, err := argparse.NewArgumentParser(map[string]any{"prog": "mytool", "addHelp": false})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"source"}})
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"pair"}, Nargs: 2})
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"rest"}, Nargs: argparse.ZERO_OR_MORE})
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Required: true})
+		parser.ParseArgs([]string{"a"}, nil)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestError$")
+	cmd.Env = append(os.Environ(), "ARGPARSE_TEST_ERROR=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Fatalf("expected exit status 2, got %v", err)
+	}
+	expected := "usage: mytool --output OUTPUT source pair pair [rest ...]\nmytool: error: the following arguments are required: pair, --output\n"
+	if stderr.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stderr.String())
+	}
+}
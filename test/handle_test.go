@@ -0,0 +1,71 @@
+package argparse_test
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goimp/argparse"
+)
+
+func TestAdd(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := argparse.Add[int](parser, &argparse.Argument{OptionStrings: []string{"--port"}, Default: 80})
+	ratio := argparse.Add[float64](parser, &argparse.Argument{OptionStrings: []string{"--ratio"}})
+	name := argparse.Add[string](parser, &argparse.Argument{OptionStrings: []string{"--name"}, Default: "anonymous"})
+	dryRun := argparse.Add[bool](parser, &argparse.Argument{OptionStrings: []string{"-n", "--dry-run"}})
+	timeout := argparse.Add[time.Duration](parser, &argparse.Argument{OptionStrings: []string{"--timeout"}, Default: "30s"})
+	address := argparse.Add[netip.Addr](parser, &argparse.Argument{OptionStrings: []string{"--address"}})
+	limit := argparse.Add[*uint8](parser, &argparse.Argument{OptionStrings: []string{"--limit"}})
+	tags := argparse.Add[[]string](parser, &argparse.Argument{OptionStrings: []string{"--tag"}})
+	sizes := argparse.Add[[]int64](parser, &argparse.Argument{OptionStrings: []string{"--sizes"}, Nargs: "+"})
+	verbose := argparse.Add[int](parser, &argparse.Argument{OptionStrings: []string{"-v"}, Action: "count"})
+	group := parser.AddArgumentGroup(argparse.NewArgumentGroup(parser.ActionsContainer, "inputs", "", "", nil, nil))
+	files := argparse.Add[[]string](group, &argparse.Argument{OptionStrings: []string{"files"}})
+
+	if _, err := parser.ParseArgs(strings.Fields("--port 8080 --ratio 0.5 -n --address ::1 --tag a --tag b --sizes 1 2 --sizes 3 -vvv x y"), nil); err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		got, expected any
+	}{
+		{port.Value(), 8080},
+		{ratio.Value(), 0.5},
+		{name.Value(), "anonymous"},
+		{dryRun.Value(), true},
+		{timeout.Value(), 30 * time.Second},
+		{address.Value(), netip.MustParseAddr("::1")},
+		{limit.Value(), (*uint8)(nil)},
+		{tags.Value(), []string{"a", "b"}},
+		{sizes.Value(), []int64{1, 2, 3}},
+		{verbose.Value(), 3},
+		{files.Value(), []string{"x", "y"}},
+	}
+	for _, check := range checks {
+		if !reflect.DeepEqual(check.got, check.expected) {
+			t.Errorf("expected %#v, got %#v", check.expected, check.got)
+		}
+	}
+	if !port.Found() || port.Action().Struct().Dest != "port" {
+		t.Errorf("unexpected handle %+v", port)
+	}
+
+	// values are reset by each parse
+	if _, err := parser.ParseArgs(strings.Fields("--limit 7"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if port.Value() != 80 || dryRun.Value() || tags.Value() != nil || *limit.Value() != 7 {
+		t.Errorf("unexpected values %v %v %v %v", port.Value(), dryRun.Value(), tags.Value(), limit.Value())
+	}
+
+	checkErrors(t, parser, map[string]string{
+		"--port http":    "argument --port: invalid int value: 'http'",
+		"--limit 300":    "argument --limit: invalid uint8 value: '300'",
+		"--address host": "argument --address: invalid netip.Addr value: 'host'",
+	})
+}
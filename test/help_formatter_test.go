@@ -0,0 +1,101 @@
+package argparse_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestFormatHelp(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	parser, err := argparse.NewArgumentParser(map[string]any{
+		"prog":        "mytool",
+		"description": "A tool that does many things with a rather long description text that wraps around.",
+		"epilog":      "see the docs",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v", "--verbose"}, Action: "count", Help: "increase verbosity"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--color"}, Choices: []any{"auto", "never"}, Default: "auto", Help: "colorize output (default: %(default)s)"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--secret"}, Help: argparse.SUPPRESS})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--a-very-long-option-name"}, MetaVar: "VALUE", Help: "an option with a long name and a long help text that needs to be wrapped over several lines"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"files"}, Nargs: "*", Help: "input files"})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subparsers.AddParser("build", false, map[string]any{"help": "build it", "aliases": []string{"b"}})
+	subparsers.AddParser("test", false, map[string]any{"help": "test it"})
+
+	// the same output as CPython's argparse
+	expected := `usage: mytool [-h] [-v] [--color {auto,never}]
+              [--a-very-long-option-name VALUE]
+              [files ...] {build,b,test} ...
+
+A tool that does many things with a rather long description text that wraps
+around.
+
+positional arguments:
+  files                 input files
+  {build,b,test}
+    build (b)           build it
+    test                test it
+
+options:
+  -h, --help            show this help message and exit
+  -v, --verbose         increase verbosity
+  --color {auto,never}  colorize output (default: auto)
+  --a-very-long-option-name VALUE
+                        an option with a long name and a long help text that
+                        needs to be wrapped over several lines
+
+see the docs
+`
+	if help := parser.FormatHelp(); help != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, help)
+	}
+
+	usage := "usage: mytool [-h] [-v] [--color {auto,never}]\n"
+	if got := parser.FormatUsage(); !strings.HasPrefix(got, usage) {
+		t.Errorf("expected usage %q, got %q", usage, got)
+	}
+}
+
+func TestHelpFormatterClasses(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	newParser := func(formatterClass argparse.NewHelpFormatterFunc) *argparse.ArgumentParser {
+		parser, err := argparse.NewArgumentParser(map[string]any{
+			"prog":           "mytool",
+			"description":    "first line\n  indented line",
+			"formatterClass": formatterClass,
+			"addHelp":        false,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80, Help: "listen\nport"})
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"name"}, Help: "the name"})
+		return parser
+	}
+
+	tests := []struct {
+		formatterClass argparse.NewHelpFormatterFunc
+		fragments      []string
+	}{
+		{argparse.NewHelpFormatter, []string{"first line indented line\n", "--port PORT  listen port\n"}},
+		{argparse.NewRawDescriptionHelpFormatter, []string{"first line\n  indented line\n", "--port PORT  listen port\n"}},
+		{argparse.NewRawTextHelpFormatter, []string{"first line\n  indented line\n", "--port PORT  listen\n               port\n"}},
+		{argparse.NewArgumentDefaultsHelpFormatter, []string{"--port PORT  listen port (default: 80)\n", "name         the name\n"}},
+		{argparse.NewMetaVarTypeHelpFormatter, []string{"usage: mytool [--port int] str\n", "--port int  listen port\n"}},
+	}
+	for _, test := range tests {
+		help := newParser(test.formatterClass).FormatHelp()
+		for _, fragment := range test.fragments {
+			if !strings.Contains(help, fragment) {
+				t.Errorf("help does not contain %q:\n%s", fragment, help)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package argparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+// writeConfig writes a configuration file in a temporary directory.
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// checkNamespace compares the values of the given dests.
+func checkNamespace(t *testing.T, ns *argparse.Namespace, expected map[string]any) {
+	t.Helper()
+	for dest, value := range expected {
+		if got, _ := ns.Get(dest); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s: expected %#v, got %#v", dest, value, got)
+		}
+	}
+}
+
+// checkErrors parses each space separated command line and compares the error.
+func checkErrors(t *testing.T, parser *argparse.ArgumentParser, expected map[string]string) {
+	t.Helper()
+	for args, message := range expected {
+		if _, err := parser.ParseArgs(strings.Fields(args), nil); err == nil || err.Error() != message {
+			t.Errorf("%s: expected error %q, got %v", args, message, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package argparse_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	parser, err := argparse.NewArgumentParser(map[string]any{
+		"exitOnError": false,
+		"prog":        "mytool",
+		"envPrefix":   "MYTOOL_",
+		// expand the short alias of the build command
+		"beforeParse": func(args []string) ([]string, error) {
+			for i, arg := range args {
+				if arg == "b" {
+					args[i] = "build"
+				}
+			}
+			return args, nil
+		},
+		"onAction": func(action argparse.ActionInterface, values any, optionString string) error {
+			calls = append(calls, fmt.Sprintf("%s=%v", action.Struct().Dest, values))
+			if values == "panic" {
+				return errors.New("invalid log level")
+			}
+			return nil
+		},
+		"afterParse": func(ns *argparse.Namespace) error {
+			if output, found := ns.Get("output"); found && output != nil {
+				ns.Set("output", strings.TrimSuffix(output.(string), "/"))
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--user"}})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}})
+
+	t.Setenv("MYTOOL_USER", "alice")
+	ns, err := parser.ParseArgs([]string{"--log-level", "debug", "b", "--output", "dist/"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"log_level": "debug", "command": "build", "output": "dist", "user": "alice"})
+
+	// the actions of the subparsers and of the environment are reported too
+	expected := "log_level=debug output=dist/ command=[build --output dist/] user=alice"
+	if got := strings.Join(calls, " "); got != expected {
+		t.Errorf("expected calls %q, got %q", expected, got)
+	}
+
+	// the hooks abort parsing with an error
+	if _, err = parser.ParseArgs([]string{"--log-level", "panic"}, nil); err == nil || err.Error() != "invalid log level" {
+		t.Errorf("unexpected error %v", err)
+	}
+	build.AfterParse = func(ns *argparse.Namespace) error { return errors.New("no targets") }
+	if _, err = parser.ParseArgs([]string{"build"}, nil); err == nil || err.Error() != "no targets" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
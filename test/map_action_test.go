@@ -0,0 +1,70 @@
+package argparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestMapAction(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--label"}, Action: "map", Default: map[string]any{"env": "dev"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--limit"}, Action: "map", Type: "int", KeySeparator: ":", DuplicateKeys: argparse.DUPLICATE_KEYS_ERROR, Env: argparse.SUPPRESS})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--set"}, Action: "extend_map", Env: argparse.SUPPRESS})
+
+	ns, err := parser.ParseArgs(strings.Fields("--label team=core --label env=prod --limit cpu:2 --set a.b=c x=y=z --set a.b=d"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{
+		"label": map[string]any{"env": "prod", "team": "core"},
+		"limit": map[string]any{"cpu": 2},
+		"set":   map[string]any{"a.b": "d", "x": "y=z"},
+	})
+	if labels, err := argparse.Get[map[string]string](ns, "label"); err != nil || !reflect.DeepEqual(labels, map[string]string{"env": "prod", "team": "core"}) {
+		t.Errorf("unexpected labels %v, %v", labels, err)
+	}
+
+	checkErrors(t, parser, map[string]string{
+		"--label env":                 "argument --label: expected KEY=VALUE, got 'env'",
+		"--label =x":                  "argument --label: expected KEY=VALUE, got '=x'",
+		"--limit cpu:x":               "argument --limit: invalid int value: 'x'",
+		"--limit cpu:1 --limit cpu:2": "argument --limit: duplicate key: 'cpu'",
+	})
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{"[--label KEY=VALUE]", "[--limit KEY:VALUE]", "[--set KEY=VALUE [KEY=VALUE ...]]"} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+
+	// the environment and the sections of configuration files
+	t.Setenv("MYTOOL_LABEL", "env=test,team=web")
+	ns, err = parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"label": map[string]any{"env": "test", "team": "web"}, "limit": nil, "set": nil})
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[limit]\ncpu = 2\nmemory = 512\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parser.ConfigFiles = []string{path}
+	ns, err = parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits, _ := ns.Get("limit"); !reflect.DeepEqual(limits, map[string]any{"cpu": 2, "memory": 512}) {
+		t.Errorf("unexpected limits %v", limits)
+	}
+}
@@ -0,0 +1,65 @@
+package argparse_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goimp/argparse"
+)
+
+func TestNamespaceAccessors(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v"}, Action: "count", Dest: "verbose"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-q"}, Action: "count", Dest: "quiet"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Action: "extend", Nargs: "+", Type: "int"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--ratio"}, Type: "float"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--timeout"}, Default: "30s"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--name"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+
+	ns, err := parser.ParseArgs(strings.Fields("-vv --tag a --tag b --port 80 443 --ratio 0.5 --name x"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		got, expected any
+	}{
+		{ns.MustGetInt("verbose"), 2},
+		{ns.MustGetInt("quiet"), 0},
+		{ns.MustGetStrings("tag"), []string{"a", "b"}},
+		{ns.MustGetInts("port"), []int{80, 443}},
+		{ns.MustGetFloat("ratio"), 0.5},
+		{ns.MustGetDuration("timeout"), 30 * time.Second},
+		{ns.MustGetString("name"), "x"},
+		{ns.MustGetBool("dry_run"), false},
+		{argparse.MustGet[[]uint16](ns, "port"), []uint16{80, 443}},
+	}
+	for _, check := range checks {
+		if !reflect.DeepEqual(check.got, check.expected) {
+			t.Errorf("expected %#v, got %#v", check.expected, check.got)
+		}
+	}
+
+	if _, err := ns.GetInt("name"); err == nil || err.Error() != `cannot decode 'name' into int: string value "x" is not compatible with int` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := argparse.Get[[]int8](ns, "port"); err == nil || err.Error() != "cannot decode 'port' into []int8[1]: value 443 overflows int8" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := ns.GetString("missing"); err == nil || err.Error() != "'Namespace' object has no attribute 'missing'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustGetBool to panic")
+		}
+	}()
+	ns.MustGetBool("tag")
+}
@@ -0,0 +1,129 @@
+package argparse_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func newTestNamespace() *argparse.Namespace {
+	ns := argparse.NewNamespace(nil)
+	ns.Set("f", 1.0)
+	ns.Set("e", []any{1, "y"})
+	ns.Set("d", true)
+	ns.Set("log-level", nil)
+	ns.Set("c", nil)
+	ns.Set("b", "x")
+	ns.Set("a", 1)
+	return ns
+}
+
+func TestNamespaceRepr(t *testing.T) {
+	ns := newTestNamespace()
+	expected := "Namespace(a=1, b='x', c=None, d=True, e=[1, 'y'], f=1.0, **{'log-level': None})"
+	for i := 0; i < 10; i++ {
+		if repr := ns.Repr(); repr != expected {
+			t.Fatalf("expected %s, got %s", expected, repr)
+		}
+	}
+
+	values := argparse.NewNamespace(nil)
+	values.Set("quote", "it's")
+	values.Set("big", 1e20)
+	values.Set("inf", math.Inf(1))
+	values.Set("sub", argparse.NewNamespace(map[string]any{"x": false}))
+	expected = `Namespace(big=1e+20, inf=inf, quote="it's", sub=Namespace(x=False))`
+	if repr := values.Repr(); repr != expected {
+		t.Errorf("expected %s, got %s", expected, repr)
+	}
+}
+
+func TestNamespaceKeys(t *testing.T) {
+	ns := newTestNamespace()
+	if keys := ns.Keys(); !reflect.DeepEqual(keys, []string{"a", "b", "c", "d", "e", "f", "log-level"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	clone := ns.Clone()
+	items, _ := clone.Get("e")
+	items.([]any)[0] = 2
+	clone.Delete("a")
+	if value, _ := ns.Get("e"); value.([]any)[0] != 1 || !ns.Contains("a") || clone.Contains("a") {
+		t.Errorf("clone is not independent: %s %s", ns.Repr(), clone.Repr())
+	}
+
+	other := argparse.NewNamespace(map[string]any{"a": 3, "z": "new"})
+	clone.Merge(other)
+	if clone.Repr() != "Namespace(a=3, b='x', c=None, d=True, e=[2, 'y'], f=1.0, z='new', **{'log-level': None})" {
+		t.Errorf("unexpected merge %s", clone.Repr())
+	}
+}
+
+func TestNamespaceJSON(t *testing.T) {
+	ns := newTestNamespace()
+	data, err := json.Marshal(ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"a":1,"b":"x","c":null,"d":true,"e":[1,"y"],"f":1,"log-level":null}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	decoded := argparse.NewNamespace(nil)
+	if err := json.Unmarshal([]byte(`{"a":1,"b":"x","e":[1,"y"],"f":1.5,"m":{"k":2}}`), decoded); err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, decoded, map[string]any{"a": 1, "b": "x", "e": []any{1, "y"}, "f": 1.5, "m": map[string]any{"k": 2}})
+
+	if err := json.Unmarshal([]byte(`[1]`), decoded); err == nil {
+		t.Errorf("expected an error for a JSON array")
+	}
+}
+
+func TestNamespaceYAML(t *testing.T) {
+	ns := newTestNamespace()
+	ns.Set("empty", []string{})
+	ns.Set("port", "8080")
+	ns.Set("server", argparse.NewNamespace(map[string]any{"host": "localhost", "tags": []string{"a", "b c"}}))
+	data, err := ns.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `a: 1
+b: x
+c: null
+d: true
+e:
+  - 1
+  - y
+empty: []
+f: 1.0
+log-level: null
+port: "8080"
+server:
+  host: localhost
+  tags:
+    - a
+    - b c
+`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	entries, err := argparse.ParseYAMLConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 11 {
+		t.Errorf("expected 11 entries, got %+v", entries)
+	}
+
+	ns.Set("callback", func() {})
+	if _, err := ns.YAML(); err == nil || err.Error() != "callback: unsupported type func()" {
+		t.Errorf("expected unsupported type error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package argparse_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestOutputStreams(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader("s3cret\n")
+	parser, err := argparse.NewArgumentParser(map[string]any{
+		"prog":        "mytool",
+		"exitOnError": false,
+		"stdout":      &stdout,
+		"stderr":      &stderr,
+		"stdin":       stdin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--old"}, Deprecated: true})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token-stdin"}, Dest: "token", Action: "secret_stdin"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"input"}, Type: argparse.NewFileType("r", -1, "", "")})
+
+	ns, err := parser.ParseArgs([]string{"--old", "x", "--token-stdin", "-"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"token": "s3cret"})
+	if input, _ := ns.Get("input"); input != stdin {
+		t.Errorf("expected the stdin of the parser, got %v", input)
+	}
+	if message := "mytool: warning: option '--old' is deprecated\n"; stderr.String() != message {
+		t.Errorf("expected %q on stderr, got %q", message, stderr.String())
+	}
+
+	parser.PrintUsage(nil)
+	parser.PrintHelp(nil)
+	if output := stdout.String(); !strings.HasPrefix(output, "usage: mytool [-h]") || !strings.Contains(output, "options:\n") {
+		t.Errorf("unexpected stdout %q", output)
+	}
+
+	// the default prompter reads the stdin of the parser, not a terminal
+	if parser.Prompter.Interactive() {
+		t.Errorf("a reader is not a terminal")
+	}
+}
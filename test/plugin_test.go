@@ -0,0 +1,118 @@
+package argparse_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	plugins := map[string]string{
+		"mytool-hello": "#!/bin/sh\necho \"hello $* $MYTOOL_USER $MYTOOL_TAG $MYTOOL_LABEL token=${MYTOOL_TOKEN-unset}\"\nexit 3\n",
+		"mytool-build": "#!/bin/sh\necho plugin build\n",
+		"mytool-data":  "not executable",
+	}
+	for name, content := range plugins {
+		mode := os.FileMode(0o755)
+		if name == "mytool-data" {
+			mode = 0o644
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--user"}, Default: "nobody"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--label"}, Action: "map"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token"}, Sensitive: true})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command", "pluginPrefix": "mytool-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := subparsers.AddParser("build", false, map[string]any{"help": "build the project"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the parser runs the plugin and exits in a child process running this test
+	if os.Getenv("ARGPARSE_TEST_PLUGIN") == "1" {
+		parser.ParseArgs(strings.Fields("--user alice --tag a --tag b --label env=prod --label app=web --token s3cret hello --loud world"), nil)
+		return
+	}
+
+	if plugins := parser.Subparsers.Plugins; len(plugins) != 1 || filepath.Base(plugins["hello"]) != "mytool-hello" {
+		t.Errorf("unexpected plugins %v", plugins)
+	}
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{"{hello,build}", "hello            plugin mytool-hello", "build            build the project"} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+	script, err := parser.FormatCompletion(argparse.BASH)
+	if err != nil || !strings.Contains(script, "hello") {
+		t.Errorf("completion does not offer the plugin (%v):\n%s", err, script)
+	}
+
+	// the parsers are not replaced by the plugins
+	ns, err := parser.ParseArgs([]string{"build"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"command": "build"})
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPlugin$")
+	cmd.Env = append(os.Environ(), "ARGPARSE_TEST_PLUGIN=1")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+	if expected := "hello --loud world alice a,b app=web,env=prod token=unset\n"; stdout.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestDiscoverPluginsRelativePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mytool-local"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// the current directory is not searched, whether empty or relative
+	t.Setenv("PATH", strings.Join([]string{"", ".", "sub/..", dir}, string(os.PathListSeparator)))
+	if plugins := argparse.DiscoverPlugins("mytool-"); len(plugins) != 1 || plugins["local"] != filepath.Join(dir, "mytool-local") {
+		t.Errorf("unexpected plugins %v", plugins)
+	}
+}
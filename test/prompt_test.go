@@ -0,0 +1,127 @@
+package argparse_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+// scriptedPrompter answers the prompts with the given lines.
+type scriptedPrompter struct {
+	answers     []string
+	interactive bool
+	transcript  strings.Builder
+}
+
+func (p *scriptedPrompter) Interactive() bool {
+	return p.interactive
+}
+
+func (p *scriptedPrompter) Ask(message string, hidden bool) (string, error) {
+	p.transcript.WriteString(message)
+	if len(p.answers) == 0 {
+		return "", io.EOF
+	}
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	if hidden {
+		p.transcript.WriteString("***\n")
+	} else {
+		p.transcript.WriteString(answer + "\n")
+	}
+	return answer, nil
+}
+
+func (p *scriptedPrompter) Tell(message string) {
+	p.transcript.WriteString(message + "\n")
+}
+
+func TestPrompt(t *testing.T) {
+	prompter := &scriptedPrompter{interactive: true, answers: []string{"", "alice", "secret", "5", "2", "y"}}
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prompter": prompter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--user"}, Required: true, Prompt: "User name", Help: "the account to log in with"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--password"}, Required: true, Prompt: "Password", PromptHidden: true})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--retries"}, Type: "int", Choices: []any{1, 2, 3}, Default: 1, Prompt: "Retries"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--force"}, Action: "store_true", Prompt: "Overwrite the files?"})
+
+	ns, err := parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"user": "alice", "password": "secret", "retries": 2, "force": true})
+	if source, _ := ns.Source("user"); source.Kind != argparse.SOURCE_PROMPT {
+		t.Errorf("unexpected source %v", source)
+	}
+
+	expected := `the account to log in with
+User name: 
+error: a value is required
+the account to log in with
+User name: alice
+Password: ***
+Retries (1, 2, 3) [1]: 5
+error: invalid choice: '5' (choose from 1, 2, 3)
+Retries (1, 2, 3) [1]: 2
+Overwrite the files? [y/N]: y
+`
+	if transcript := prompter.transcript.String(); transcript != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, transcript)
+	}
+
+	// the given arguments are not asked for, empty answers keep the defaults
+	parser.Prompter = &scriptedPrompter{interactive: true, answers: []string{"", ""}}
+	ns, err = parser.ParseArgs(strings.Fields("--user bob --password x"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"user": "bob", "password": "x", "retries": 1, "force": false})
+}
+
+func TestPromptNonInteractive(t *testing.T) {
+	prompter := &scriptedPrompter{answers: []string{"alice"}}
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prompter": prompter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--user"}, Required: true, Prompt: "User name"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--password"}, Required: true, Prompt: "Password", PromptHidden: true})
+
+	_, err = parser.ParseArgs([]string{}, nil)
+	if message := "the following arguments are required: --user, --password"; err == nil || err.Error() != message {
+		t.Errorf("expected error %q, got %v", message, err)
+	}
+	if prompter.transcript.Len() != 0 {
+		t.Errorf("unexpected prompts %q", prompter.transcript.String())
+	}
+
+	// the end of the input stops prompting
+	parser.Prompter = &scriptedPrompter{interactive: true, answers: []string{"alice"}}
+	_, err = parser.ParseArgs([]string{}, nil)
+	if message := "the following arguments are required: --password"; err == nil || err.Error() != message {
+		t.Errorf("expected error %q, got %v", message, err)
+	}
+}
+
+func TestPromptRetry(t *testing.T) {
+	// no item of an invalid answer is taken
+	prompter := &scriptedPrompter{interactive: true, answers: []string{"1,x", "2,3"}}
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prompter": prompter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Action: "append", Type: "int", Required: true, Prompt: "Ports"})
+
+	ns, err := parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"port": []any{2, 3}})
+	if expected := "Ports: 1,x\nerror: invalid int value: 'x'\nPorts: 2,3\n"; prompter.transcript.String() != expected {
+		t.Errorf("expected %q, got %q", expected, prompter.transcript.String())
+	}
+}
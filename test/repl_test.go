@@ -0,0 +1,173 @@
+package argparse_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goimp/argparse"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"", nil},
+		{"  build  -o out  ", []string{"build", "-o", "out"}},
+		{`say 'hello world' "it's \"quoted\"" a\ b`, []string{"say", "hello world", `it's "quoted"`, "a b"}},
+		{`empty '' "" x # a comment`, []string{"empty", "", "", "x"}},
+		{`joined"a b"'c'`, []string{"joineda bc"}},
+	}
+	for _, test := range tests {
+		args, err := argparse.SplitCommandLine(test.line)
+		if err != nil || !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("%q: expected %q, got %q (%v)", test.line, test.expected, args, err)
+		}
+	}
+	if _, err := argparse.SplitCommandLine(`say "hello`); err == nil || err.Error() != `unterminated quote in 'say "hello'` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestREPL(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	parser, err := argparse.NewArgumentParser(map[string]any{
+		"prog":   "mytool",
+		"stdout": &stdout,
+		"stderr": &stderr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command", "required": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	greet, err := subparsers.AddParser("greet", false, map[string]any{"help": "greet someone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	greet.AddArgument(&argparse.Argument{OptionStrings: []string{"name"}})
+	greet.AddArgument(&argparse.Argument{OptionStrings: []string{"--times"}, Type: "int", Default: 1})
+
+	var calls []string
+	repl := argparse.NewREPL(parser, func(ns *argparse.Namespace) error {
+		name := ns.MustGetString("name")
+		if name == "nobody" {
+			return fmt.Errorf("nobody to greet")
+		}
+		calls = append(calls, fmt.Sprintf("%s x%d", name, ns.MustGetInt("times")))
+		return nil
+	})
+
+	if err := repl.Execute(`greet "Ada Lovelace" --times 2`); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(calls, []string{"Ada Lovelace x2"}) {
+		t.Errorf("unexpected calls %q", calls)
+	}
+
+	// the parsers report their errors and the help without exiting
+	if err := repl.Execute("greet bob --times x"); err == nil || err.Error() != "exit status 2" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if message := "mytool greet: error: argument --times: invalid int value: 'x'\n"; !strings.HasSuffix(stderr.String(), message) {
+		t.Errorf("expected %q, got %q", message, stderr.String())
+	}
+	if err := repl.Execute("greet --help"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if !strings.Contains(stdout.String(), "usage: mytool greet [-h] [--times TIMES] name") {
+		t.Errorf("unexpected help %q", stdout.String())
+	}
+
+	// the errors of the handler are reported
+	stderr.Reset()
+	if err := repl.Execute("greet nobody"); err == nil || stderr.String() != "mytool: error: nobody to greet\n" {
+		t.Errorf("unexpected error %v, %q", err, stderr.String())
+	}
+
+	// the help built-in shows the help of the subcommands
+	stdout.Reset()
+	if err := repl.Execute("help greet"); err != nil || !strings.HasPrefix(stdout.String(), "usage: mytool greet") || !strings.Contains(stdout.String(), "built-in commands") {
+		t.Errorf("unexpected help %q (%v)", stdout.String(), err)
+	}
+	if err := repl.Execute("help missing"); err == nil || err.Error() != "unknown command 'missing'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// the scripts run until exit
+	calls = nil
+	parser.Stdin = strings.NewReader("# greetings\ngreet alice\n\ngreet bob --times 3\nexit\ngreet carol\n")
+	if err := repl.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"alice x1", "bob x3"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %q, got %q", expected, calls)
+	}
+
+	// the script stops at the first failing command
+	calls = nil
+	stderr.Reset()
+	parser.Stdin = strings.NewReader("greet alice\ngreet\ngreet bob\n")
+	if err := repl.Run(); err == nil || err.Error() != "exit status 2" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"alice x1"}) || !strings.Contains(stderr.String(), "the following arguments are required: name") {
+		t.Errorf("unexpected calls %q, stderr %q", calls, stderr.String())
+	}
+}
+
+func TestREPLCompleteLine(t *testing.T) {
+	// tab after secret_stdin returns without reading stdin, which never ends
+	stdin, writer := io.Pipe()
+	defer writer.Close()
+	parser, err := argparse.NewArgumentParser(map[string]any{"prog": "mytool", "stdin": stdin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token-stdin"}, Dest: "token", Action: "secret_stdin"})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command", "required": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	greet, err := subparsers.AddParser("greet", false, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	greet.AddArgument(&argparse.Argument{OptionStrings: []string{"name"}})
+	greet.AddArgument(&argparse.Argument{OptionStrings: []string{"--times"}, Type: "int"})
+	repl := argparse.NewREPL(parser, func(ns *argparse.Namespace) error { return nil })
+
+	tests := []struct {
+		line       string
+		expected   string
+		candidates []string
+	}{
+		{"gr", "greet ", nil},
+		{"--token-stdin gr", "--token-stdin greet ", nil},
+		{"--token-stdin greet --t", "--token-stdin greet --times ", nil},
+		{"greet ali", "greet ali", nil},
+		{"--", "--", []string{"--help", "--token-stdin"}},
+	}
+	for _, test := range tests {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			line, candidates := repl.CompleteLine(test.line)
+			if got := candidateValues(candidates); line != test.expected || len(got)+len(test.candidates) > 0 && !reflect.DeepEqual(got, test.candidates) {
+				t.Errorf("%q: expected %q %q, got %q %q", test.line, test.expected, test.candidates, line, got)
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%q: completion blocked reading stdin", test.line)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package argparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestSensitiveMasking(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{
+		"prog":           "mytool",
+		"exitOnError":    false,
+		"formatterClass": argparse.NewArgumentDefaultsHelpFormatter,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--user"}, Default: "admin", Help: "the user"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--password"}, Default: "changeme", Sensitive: true, Help: "the password"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--pin"}, Type: "int", Sensitive: true})
+
+	ns, err := parser.ParseArgs(strings.Fields("--user bob --password hunter2"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"user": "bob", "password": "hunter2"})
+	if !ns.IsSensitive("password") || ns.IsSensitive("user") {
+		t.Errorf("unexpected sensitive dests")
+	}
+
+	if repr, expected := ns.Repr(), "Namespace(password='********', pin=None, user='bob')"; repr != expected {
+		t.Errorf("expected %s, got %s", expected, repr)
+	}
+	if data, err := ns.MarshalJSON(); err != nil || string(data) != `{"password":"********","pin":null,"user":"bob"}` {
+		t.Errorf("unexpected JSON %s (%v)", data, err)
+	}
+	if data, err := ns.YAML(); err != nil || strings.Contains(string(data), "hunter2") {
+		t.Errorf("unexpected YAML %s (%v)", data, err)
+	}
+	if config := parser.FormatConfig(ns); strings.Contains(config, "hunter2") {
+		t.Errorf("unexpected config %s", config)
+	}
+	if clone := ns.Clone(); !clone.IsSensitive("password") {
+		t.Errorf("clone lost the sensitive dests")
+	}
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{"the password (default: ********)", "the user (default: admin)"} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+	if strings.Contains(help, "changeme") {
+		t.Errorf("help shows the sensitive default:\n%s", help)
+	}
+
+	checkErrors(t, parser, map[string]string{
+		"--pin 12ab": "argument --pin: invalid int value: '********'",
+		"--password": "argument --password: expected one argument",
+	})
+}
+
+func TestSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--password"}, Default: "changeme", Sensitive: true})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--password-file"}, Dest: "password", Action: "secret_file"})
+
+	ns, err := parser.ParseArgs([]string{"--password-file", path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"password": "s3cret"})
+	if strings.Contains(ns.Repr(), "s3cret") {
+		t.Errorf("unexpected %s", ns.Repr())
+	}
+
+	// the default of the argument is kept without the file
+	ns, err = parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{"password": "changeme"})
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err = parser.ParseArgs([]string{"--password-file", missing}, nil); err == nil || !strings.HasPrefix(err.Error(), "argument --password-file: can't open") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSensitiveMap(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--secret"}, Action: "map", Type: "int", Sensitive: true, Env: "MYTOOL_SECRET"})
+
+	// the values of the items are masked, whatever their source
+	checkErrors(t, parser, map[string]string{
+		"--secret k=hunter2": "argument --secret: invalid int value: '********'",
+	})
+	config := writeConfig(t, "c.json", `{"secret": {"k": "hunter4"}}`)
+	checkErrors(t, parser, map[string]string{
+		"--config " + config: "argument --secret: invalid int value: '********' (from " + config + ":1)",
+	})
+	t.Setenv("MYTOOL_SECRET", "k=hunter5")
+	checkErrors(t, parser, map[string]string{
+		"": "argument --secret: invalid int value: '********' (from environment variable MYTOOL_SECRET)",
+	})
+}
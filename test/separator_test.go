@@ -0,0 +1,76 @@
+package argparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestSeparator(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tags"}, Action: "append", Separator: ","})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--sizes"}, Action: "extend", Nargs: "+", Type: "int", Separator: ","})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--path"}, Separator: ":", Choices: []any{"/bin", "/usr/bin", "a:b"}})
+	ns, err := parser.ParseArgs([]string{"--tags", `a,"b,c",d\,e`, "--tags", "f", "--sizes", "1,2", "3", "--path", `/bin:"a:b"`}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{
+		"tags":  []any{"a", "b,c", "d,e", "f"},
+		"sizes": []any{1, 2, 3},
+		"path":  []any{"/bin", "a:b"},
+	})
+
+	checkErrors(t, parser, map[string]string{
+		"--sizes 1,x":       "argument --sizes: invalid int value: 'x'",
+		"--path /bin:/sbin": "argument --path: invalid choice: '/sbin' (choose from /bin, /usr/bin, a:b)",
+		`--tags "a`:         `argument --tags: unterminated quote in '"a'`,
+		`--tags "a"b`:       `argument --tags: unexpected character after quoted field in '"a"b'`,
+	})
+}
+
+func TestSeparatorSources(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tags"}, Action: "append", Separator: ","})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--sizes"}, Action: "extend", Nargs: "+", Type: "int"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--path"}, Separator: ":"})
+	t.Setenv("MYTOOL_TAGS", `x,"y,z"`)
+	t.Setenv("MYTOOL_PATH", "/bin:/usr/bin")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"sizes": [1, 2], "tags": ["ignored"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parser.ConfigFiles = []string{path}
+	ns, err := parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNamespace(t, ns, map[string]any{
+		"tags":  []any{"x", "y,z"},
+		"sizes": []any{1, 2},
+		"path":  []any{"/bin", "/usr/bin"},
+	})
+
+	// the items of arrays are not split
+	if err := os.WriteFile(path, []byte(`{"tags": ["a,b", "c"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("MYTOOL_TAGS")
+	ns, err = parser.ParseArgs([]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := ns.Get("tags"); !reflect.DeepEqual(tags, []any{"a,b", "c"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
@@ -0,0 +1,108 @@
+package argparse_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestNamespaceSource(t *testing.T) {
+	path := writeConfig(t, "config.toml", "host = \"example.com\"\nuser = \"admin\"\n\n[build]\njobs = 4\n")
+	t.Setenv("MYTOOL_RATIO", "0.5")
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--host"}, Default: "localhost"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--ratio"}, Type: "float"})
+	parser.SetDefaults(map[string]any{"user": "nobody"})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"--jobs"}, Type: "int"})
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"target"}})
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"files"}, Nargs: "*"})
+
+	ns, err := parser.ParseArgs([]string{"--config", path, "--port", "1", "build", "all"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]argparse.Source{
+		"config":    {Kind: argparse.SOURCE_COMMAND_LINE, Name: "--config", Index: 0},
+		"port":      {Kind: argparse.SOURCE_COMMAND_LINE, Name: "--port", Index: 2},
+		"command":   {Kind: argparse.SOURCE_COMMAND_LINE, Name: "command", Index: 4},
+		"target":    {Kind: argparse.SOURCE_COMMAND_LINE, Name: "target", Index: 5},
+		"files":     {Kind: argparse.SOURCE_DEFAULT},
+		"jobs":      {Kind: argparse.SOURCE_CONFIG, Name: path, Line: 5},
+		"host":      {Kind: argparse.SOURCE_CONFIG, Name: path, Line: 1},
+		"user":      {Kind: argparse.SOURCE_CONFIG, Name: path, Line: 2},
+		"ratio":     {Kind: argparse.SOURCE_ENV, Name: "MYTOOL_RATIO"},
+		"log_level": {Kind: argparse.SOURCE_DEFAULT},
+	}
+	for dest, source := range expected {
+		if got, found := ns.Source(dest); !found || got != source {
+			t.Errorf("%s: expected source %#v, got %#v", dest, source, got)
+		}
+	}
+
+	// parser defaults
+	ns, err = parser.ParseArgs([]string{"build", "all"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source, _ := ns.Source("user"); source.Kind != argparse.SOURCE_PARSER_DEFAULT {
+		t.Errorf("expected a parser default, got %#v", source)
+	}
+	if _, found := argparse.NewNamespace(map[string]any{"a": 1}).Source("a"); found {
+		t.Errorf("expected no source for a value not set by the parser")
+	}
+}
+
+func TestFormatConfig(t *testing.T) {
+	path := writeConfig(t, "config.json", "{\n  \"host\": \"example.com\"\n}")
+	t.Setenv("MYTOOL_DRY_RUN", "1")
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--config"}, Action: "config"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--host"}, Default: "localhost"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--log-level"}, Choices: []any{"debug", "info"}})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tag"}, Action: "append"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dry-run"}, Action: "store_true"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--ratio"}, Type: "float"})
+	parser.SetDefaults(map[string]any{"user": "nobody"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--print-config"}, Action: "print_config"})
+	ns, _, err := parser.ParseKnownArgs([]string{"--config", path, "--port", "8080", "--print-config"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	width := len("config = []") + len(path)
+	line := func(value string, source string) string {
+		return fmt.Sprintf("%-*s  # %s\n", width, value, source)
+	}
+	expected := line("config = ["+path+"]", "command line --config (argument 0)") +
+		line("dry_run = True", "environment variable MYTOOL_DRY_RUN") +
+		line("host = example.com", "config file "+path+":2") +
+		line("log_level = None", "default") +
+		line("port = 8080", "command line --port (argument 2)") +
+		line("ratio = None", "default") +
+		line("tag = None", "default") +
+		line("user = nobody", "parser default")
+	if got := parser.FormatConfig(ns); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
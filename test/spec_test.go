@@ -0,0 +1,82 @@
+package argparse_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestSpec(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool", "description": "does things", "envPrefix": "MYTOOL_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"-v", "--verbose"}, Action: "count", Help: "more output"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--token"}, Default: "s3cret", Sensitive: true})
+	group := parser.AddArgumentGroup(argparse.NewArgumentGroup(parser.ActionsContainer, "network", "how to connect", "", nil, nil))
+	group.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Default: 80, Choices: []any{80, 443}})
+	mutex := parser.AddMutuallyExclusiveGroup(argparse.NewMutuallyExclusiveGroup("", "", nil, nil))
+	mutex.(*argparse.MutuallyExclusiveGroup).Required = true
+	mutex.AddArgument(&argparse.Argument{OptionStrings: []string{"--json"}, Action: "store_true"})
+	mutex.AddArgument(&argparse.Argument{OptionStrings: []string{"--yaml"}, Action: "store_true"})
+	subparsers, err := parser.AddSubparsers(map[string]any{"dest": "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := subparsers.AddParser("build", false, map[string]any{"help": "build it", "aliases": []string{"b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.AddArgument(&argparse.Argument{OptionStrings: []string{"targets"}, Nargs: argparse.ONE_OR_MORE})
+
+	data, err := parser.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec argparse.ParserSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.SpecVersion != argparse.SPEC_VERSION || spec.Prog != "mytool" || spec.Description != "does things" {
+		t.Errorf("unexpected parser %+v", spec)
+	}
+	kinds := []string{}
+	for _, action := range spec.Actions {
+		kinds = append(kinds, action.Kind)
+	}
+	if expected := []string{"help", "count", "store", "store", "store_true", "store_true", "parsers"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected kinds %q, got %q", expected, kinds)
+	}
+
+	verbose, token, port := spec.Actions[1], spec.Actions[2], spec.Actions[3]
+	if !reflect.DeepEqual(verbose.OptionStrings, []string{"-v", "--verbose"}) || verbose.Dest != "verbose" || verbose.Nargs != float64(0) || verbose.Help != "more output" || verbose.Env != "MYTOOL_VERBOSE" {
+		t.Errorf("unexpected action %+v", verbose)
+	}
+	if token.Default != argparse.SENSITIVE_MASK || !token.Sensitive {
+		t.Errorf("unexpected action %+v", token)
+	}
+	if port.Type != "int" || port.Default != float64(80) || !reflect.DeepEqual(port.Choices, []any{float64(80), float64(443)}) {
+		t.Errorf("unexpected action %+v", port)
+	}
+
+	if groups := spec.Groups; len(groups) != 3 || groups[2].Title != "network" || !reflect.DeepEqual(groups[2].Actions, []int{3}) {
+		t.Errorf("unexpected groups %+v", groups)
+	}
+	if mutexes := spec.MutuallyExclusiveGroups; len(mutexes) != 1 || !mutexes[0].Required || !reflect.DeepEqual(mutexes[0].Actions, []int{4, 5}) {
+		t.Errorf("unexpected mutually exclusive groups %+v", mutexes)
+	}
+
+	if spec.Subparsers == nil || spec.Subparsers.Action != 6 || len(spec.Subparsers.Commands) != 1 {
+		t.Fatalf("unexpected subparsers %+v", spec.Subparsers)
+	}
+	command := spec.Subparsers.Commands[0]
+	if command.Name != "build" || !reflect.DeepEqual(command.Aliases, []string{"b"}) || command.Help != "build it" || command.Parser == nil || command.Parser.SpecVersion != 0 {
+		t.Fatalf("unexpected command %+v", command)
+	}
+	if targets := command.Parser.Actions[1]; targets.Dest != "targets" || targets.Nargs != argparse.ONE_OR_MORE || !targets.Required {
+		t.Errorf("unexpected action %+v", targets)
+	}
+}
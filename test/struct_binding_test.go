@@ -0,0 +1,178 @@
+package argparse_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goimp/argparse"
+)
+
+type GlobalOptions struct {
+	Verbose int  `argparse:"-v,--verbose" action:"count" help:"increase verbosity"`
+	Quiet   bool `argparse:"-q" dest:"quiet" help:"no output"`
+}
+
+type BuildCommand struct {
+	Output  string        `argparse:"-o,--output" default:"out" help:"output directory"`
+	Jobs    int           `argparse:"-j" dest:"jobs"`
+	Timeout time.Duration `help:"build timeout"`
+	Targets []string      `argparse:"targets" nargs:"+"`
+}
+
+type TestCommand struct {
+	Run    *string `help:"run only the matching tests"`
+	Format struct {
+		JSON bool `argparse:"--json"`
+		XML  bool `argparse:"--xml"`
+	} `mutex:"true"`
+}
+
+type CLI struct {
+	GlobalOptions
+	Config  string   `argparse:"-c,--config" metavar:"FILE" help:"configuration file"`
+	Color   string   `choices:"auto,always,never" default:"auto"`
+	Port    uint16   `env:"PORT"`
+	Tags    []string `argparse:"--tag"`
+	Ignored string   `argparse:"-"`
+	Network struct {
+		Host    string `default:"localhost"`
+		Retries int    `choices:"1,2,3"`
+	} `group:"network options" help:"how to connect"`
+	Command struct {
+		Build *BuildCommand `command:"build" aliases:"b" help:"build the targets"`
+		Test  *TestCommand  `command:"test" help:"run the tests"`
+	} `dest:"command" required:"true"`
+}
+
+func TestAddStruct(t *testing.T) {
+	t.Setenv("PORT", "8080")
+	cli := CLI{Ignored: "kept"}
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding, err := parser.AddStruct(&cli)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the parser fills the struct
+	built, err := parser.ParseArgs([]string{"-vv", "--tag", "a", "--tag", "b", "--retries", "2", "b", "-j", "4", "--timeout", "1m", "x", "y"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CLI{GlobalOptions: GlobalOptions{Verbose: 2}, Color: "auto", Port: 8080, Tags: []string{"a", "b"}, Ignored: "kept"}
+	expected.Network.Host = "localhost"
+	expected.Network.Retries = 2
+	expected.Command.Build = &BuildCommand{Output: "out", Jobs: 4, Timeout: time.Minute, Targets: []string{"x", "y"}}
+	if !reflect.DeepEqual(cli, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cli)
+	}
+
+	// the unselected subcommands are nil
+	if _, err := parser.ParseArgs([]string{"test", "--run", "Foo", "--xml"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cli.Command.Build != nil || cli.Command.Test == nil || *cli.Command.Test.Run != "Foo" || !cli.Command.Test.Format.XML {
+		t.Errorf("unexpected subcommands %+v", cli.Command)
+	}
+
+	// the binding fills the struct from other namespaces
+	if err := binding.Fill(built); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cli, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cli)
+	}
+}
+
+func TestAddStructDecode(t *testing.T) {
+	type options struct {
+		Verbose bool     `argparse:"-v,--verbose"`
+		Name    string   `argparse:"-n,--user-name"`
+		Jobs    int      `argparse:"-j" dest:"jobs"`
+		Level   int      `argparse:"-l"`
+		Files   []string `argparse:"files"`
+	}
+	var bound options
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.AddStruct(&bound); err != nil {
+		t.Fatal(err)
+	}
+	ns, err := parser.ParseArgs(strings.Fields("-v --user-name bob -j 2 -l 3 a b"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tags of AddStruct name the same dests for Decode
+	var decoded options
+	if err := ns.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	expected := options{Verbose: true, Name: "bob", Jobs: 2, Level: 3, Files: []string{"a", "b"}}
+	if !reflect.DeepEqual(decoded, expected) || !reflect.DeepEqual(bound, expected) {
+		t.Errorf("expected %+v, got %+v decoded and %+v bound", expected, decoded, bound)
+	}
+}
+
+func TestAddStructErrors(t *testing.T) {
+	var cli CLI
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.AddStruct(&cli); err != nil {
+		t.Fatal(err)
+	}
+	checkErrors(t, parser, map[string]string{
+		"--retries 5 build x":    "argument --retries: invalid choice: '5' (choose from 1, 2, 3)",
+		"--port http build x":    "argument --port: invalid uint16 value: 'http'",
+		"build --timeout soon x": "argument --timeout: invalid time.Duration value: 'soon'",
+		"test --json --xml":      "argument --xml: not allowed with argument --json",
+		"":                       "the following arguments are required: command",
+	})
+
+	var invalid struct {
+		Channel chan int
+	}
+	if _, err := parser.AddStruct(&invalid); err == nil || err.Error() != ".Channel: unsupported type chan int" {
+		t.Errorf("expected unsupported type error, got %v", err)
+	}
+	var empty struct {
+		X string `argparse:",--x"`
+	}
+	if _, err := parser.AddStruct(&empty); err == nil || err.Error() != ".X: empty option string in argparse tag ',--x'" {
+		t.Errorf("expected empty option string error, got %v", err)
+	}
+}
+
+func TestAddStructHelp(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	var cli CLI
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.AddStruct(&cli); err != nil {
+		t.Fatal(err)
+	}
+
+	help := parser.FormatHelp()
+	for _, fragment := range []string{
+		"usage: mytool [-h] [-v] [-q] [-c FILE] [--color {auto,always,never}]\n",
+		"positional arguments:\n  {build,b,test}\n    build (b)",
+		"options:\n  -h, --help",
+		"  -c, --config FILE     configuration file\n",
+		"network options:\n  how to connect\n\n  --host HOST\n",
+	} {
+		if !strings.Contains(help, fragment) {
+			t.Errorf("help does not contain %q:\n%s", fragment, help)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package argparse_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := argparse.NewArgumentParser(map[string]any{"exitOnError": false, "prog": "mytool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--level"}, Type: "int", Validate: argparse.Range(1, 10)})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--ratio"}, Type: "float", Validate: argparse.Max(1)})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--port"}, Type: "int", Action: "append", Validate: argparse.Port})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--name"}, Validate: argparse.All(argparse.Length(2, 8), argparse.Match(`^[a-z]+$`))})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--url"}, Validate: argparse.URL("http", "https")})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--input"}, Validate: argparse.IsFile})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--dir"}, Validate: argparse.IsDir})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--output"}, Validate: argparse.IsWritable})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--check"}, Validate: argparse.PathExists})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--tags"}, Nargs: "*", Validate: argparse.All(argparse.NonEmpty, argparse.Unique)})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--even"}, Type: "int", Validate: func(value any) error {
+		if value.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	}})
+
+	args := []string{"--level", "10", "--ratio", "0.5", "--port", "80", "--name", "abc", "--url", "https://example.com",
+		"--input", file, "--dir", dir, "--output", filepath.Join(dir, "new.txt"), "--check", file, "--tags", "a", "b", "--even", "4"}
+	if _, err := parser.ParseArgs(args, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	checkErrors(t, parser, map[string]string{
+		"--level 11":               "argument --level: '11' is not in the range 1 to 10",
+		"--ratio 1.5":              "argument --ratio: '1.5' is greater than the maximum 1",
+		"--port 80 --port 70000":   "argument --port: invalid port: '70000' (expected 1 to 65535)",
+		"--name a":                 "argument --name: 'a' is shorter than 2 characters",
+		"--name ABC":               "argument --name: 'ABC' does not match '^[a-z]+$'",
+		"--url ftp://example.com":  "argument --url: invalid URL scheme: 'ftp' (choose from http, https)",
+		"--url example":            "argument --url: invalid URL: 'example'",
+		"--input " + dir:           "argument --input: not a file: '" + dir + "'",
+		"--dir " + file:            "argument --dir: not a directory: '" + file + "'",
+		"--output " + dir + "/x/y": "argument --output: not writable: '" + dir + "/x/y'",
+		"--check " + dir + "/x":    "argument --check: path does not exist: '" + dir + "/x'",
+		"--tags":                   "argument --tags: expected a non-empty value",
+		"--tags a b a":             "argument --tags: duplicate value: 'a'",
+		"--even 3":                 "argument --even: must be even",
+	})
+}
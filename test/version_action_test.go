@@ -0,0 +1,93 @@
+package argparse_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goimp/argparse"
+)
+
+func TestFormatVersion(t *testing.T) {
+	parser, err := argparse.NewArgumentParser(map[string]any{"prog": "mytool", "exitOnError": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--version"}, Action: "version", Version: "%(prog)s 1.2.0"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--json"}, Action: "version_json"})
+	parser.AddArgument(&argparse.Argument{OptionStrings: []string{"name"}})
+
+	action := parser.Actions[1].(*argparse.VersionAction)
+	if text := parser.FormatVersion_(action, false); text != "mytool 1.2.0\n" {
+		t.Errorf("unexpected version %q", text)
+	}
+
+	var version map[string]any
+	if err := json.Unmarshal([]byte(parser.FormatVersion_(action, true)), &version); err != nil {
+		t.Fatal(err)
+	}
+	if version["prog"] != "mytool" || version["version"] != "mytool 1.2.0" || version["goVersion"] != runtime.Version() {
+		t.Errorf("unexpected version %v", version)
+	}
+
+	// the version is derived from the build info if not given
+	action.Version = ""
+	if text, info := parser.FormatVersion_(action, false), argparse.ReadVersionInfo(); text != "mytool "+info.String()+"\n" || !strings.Contains(text, runtime.Version()) {
+		t.Errorf("unexpected version %q", text)
+	}
+
+	// --json alone does nothing
+	if _, err := parser.ParseArgs([]string{"--json", "x"}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVersionAction(t *testing.T) {
+	// the parser exits in a child process running this test
+	if args := os.Getenv("ARGPARSE_TEST_VERSION"); args != "" {
+		parser, err := argparse.NewArgumentParser(map[string]any{"prog": "mytool"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--version"}, Action: "version", Version: "%(prog)s 1.2.0"})
+		if os.Getenv("ARGPARSE_TEST_VERSION_JSON") != "" {
+			parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--json"}, Action: "version_json"})
+		}
+		parser.AddArgument(&argparse.Argument{OptionStrings: []string{"--level"}, Type: "int"})
+		parser.ParseArgs(strings.Fields(args), nil)
+		return
+	}
+
+	// the version is printed before the following arguments are converted
+	tests := []struct {
+		args   string
+		json   bool
+		output string
+	}{
+		{"--version", false, "mytool 1.2.0\n"},
+		{"--version --level abc", false, "mytool 1.2.0\n"},
+		{"--version --level abc", true, "mytool 1.2.0\n"},
+		{"--version --json", true, `"version": "mytool 1.2.0"`},
+		{"--json --version --level abc", true, `"version": "mytool 1.2.0"`},
+		{"--version --json --level abc", true, `"version": "mytool 1.2.0"`},
+	}
+	for _, test := range tests {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestVersionAction$")
+		cmd.Env = append(os.Environ(), "ARGPARSE_TEST_VERSION="+test.args)
+		if test.json {
+			cmd.Env = append(cmd.Env, "ARGPARSE_TEST_VERSION_JSON=1")
+		}
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("%s: %v", test.args, err)
+		}
+		if !strings.Contains(stdout.String(), test.output) {
+			t.Errorf("%s: expected %q, got %q", test.args, test.output, stdout.String())
+		}
+	}
+}
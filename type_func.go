@@ -0,0 +1,113 @@
+package argparse
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// inferArgument completes argument for values of Go type t: the Type
+// converting the arg strings, store_true for booleans, nargs OPTIONAL for
+// pointer positionals, and append (extend with nargs) or nargs ZERO_OR_MORE
+// for slices. It returns the type of a single value.
+func inferArgument(argument *Argument, t reflect.Type, positional bool) (reflect.Type, error) {
+	valueType := t
+	if valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+		if positional && argument.Nargs == nil {
+			argument.Nargs = OPTIONAL
+		}
+	}
+	if valueType.Kind() == reflect.Slice && !isValueType(valueType) {
+		valueType = valueType.Elem()
+		switch {
+		case positional && argument.Nargs == nil:
+			argument.Nargs = ZERO_OR_MORE
+		case !positional && argument.Action == "" && argument.Nargs == nil:
+			argument.Action = "append"
+		case !positional && argument.Action == "":
+			argument.Action = "extend"
+		}
+	}
+	if valueType.Kind() == reflect.Bool && argument.Action == "" && !positional {
+		argument.Action = "store_true"
+	}
+
+	switch argument.Action {
+	case "", "store", "append", "extend":
+		if argument.Type != nil {
+			break
+		}
+		typeFunc, err := typeFuncOf(valueType)
+		if err != nil {
+			return nil, err
+		}
+		argument.Type = typeFunc
+	}
+	return valueType, nil
+}
+
+// typeFuncOf returns the Type converting arg strings to values of t, nil for strings.
+func typeFuncOf(t reflect.Type) (Type, error) {
+	if t.Kind() == reflect.String && t == reflect.TypeOf("") {
+		return nil, nil
+	}
+	invalid := func(argString string) error {
+		return NewArgumentTypeError(fmt.Sprintf("invalid %s value: '%s'", t, argString))
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return TypeFunc(func(argString string) (any, error) {
+			value := reflect.New(t)
+			if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(argString)); err != nil {
+				return nil, invalid(argString)
+			}
+			return value.Elem().Interface(), nil
+		}), nil
+	}
+	if t == durationType {
+		return TypeFunc(func(argString string) (any, error) {
+			duration, err := time.ParseDuration(strings.TrimSpace(argString))
+			if err != nil {
+				return nil, invalid(argString)
+			}
+			return duration, nil
+		}), nil
+	}
+
+	var parse func(argString string) (any, error)
+	switch t.Kind() {
+	case reflect.String:
+		parse = func(argString string) (any, error) { return argString, nil }
+	case reflect.Bool:
+		parse = func(argString string) (any, error) { return parseBool(argString) }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parse = func(argString string) (any, error) {
+			return strconv.ParseInt(strings.TrimSpace(argString), 10, t.Bits())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parse = func(argString string) (any, error) {
+			return strconv.ParseUint(strings.TrimSpace(argString), 10, t.Bits())
+		}
+	case reflect.Float32, reflect.Float64:
+		parse = func(argString string) (any, error) { return strconv.ParseFloat(strings.TrimSpace(argString), t.Bits()) }
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+	return TypeFunc(func(argString string) (any, error) {
+		value, err := parse(argString)
+		if err != nil {
+			return nil, invalid(argString)
+		}
+		return reflect.ValueOf(value).Convert(t).Interface(), nil
+	}), nil
+}
+
+// isValueType reports whether t is a single value rather than a struct of
+// arguments or a list of values.
+func isValueType(t reflect.Type) bool {
+	return t == durationType || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
@@ -0,0 +1,293 @@
+package argparse
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateFunc checks the value of an argument after its conversion with
+// Type and its check against Choices, the value is a list for the nargs
+// producing several values. The error is reported as an ArgumentError.
+type ValidateFunc = func(value any) error
+
+// validateValues validates the values converted from the arg strings of
+// action with its Validate function.
+func validateValues(action *Action, value any) error {
+	if action.Validate == nil {
+		return nil
+	}
+	if err := action.Validate(value); err != nil {
+		var argumentErr *ArgumentError
+		if errors.As(err, &argumentErr) {
+			return err
+		}
+		return NewArgumentError(action, err.Error())
+	}
+	return nil
+}
+
+// All returns a validator running validators in order, stopping at the
+// first error.
+func All(validators ...ValidateFunc) ValidateFunc {
+	return func(value any) error {
+		for _, validate := range validators {
+			if err := validate(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// eachValue returns a validator applying validate to value, or to each of
+// its elements if value is a list.
+func eachValue(validate ValidateFunc) ValidateFunc {
+	return func(value any) error {
+		reflected := reflect.ValueOf(value)
+		if reflected.Kind() != reflect.Slice || reflected.Type().Elem().Kind() == reflect.Uint8 {
+			return validate(value)
+		}
+		for i := 0; i < reflected.Len(); i++ {
+			if err := validate(reflected.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Numeric validators
+
+// numberOf returns value as a float64 if it is an integer or a float.
+func numberOf(value any) (float64, bool) {
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), true
+	}
+	return 0, false
+}
+
+// Range returns a validator checking that numbers are between low and high
+// inclusive.
+func Range(low, high float64) ValidateFunc {
+	return eachValue(func(value any) error {
+		number, ok := numberOf(value)
+		if !ok {
+			return fmt.Errorf("'%v' is not a number", value)
+		}
+		if number < low || number > high {
+			return fmt.Errorf("'%v' is not in the range %v to %v", value, low, high)
+		}
+		return nil
+	})
+}
+
+// Min returns a validator checking that numbers are at least low.
+func Min(low float64) ValidateFunc {
+	return eachValue(func(value any) error {
+		number, ok := numberOf(value)
+		if !ok {
+			return fmt.Errorf("'%v' is not a number", value)
+		}
+		if number < low {
+			return fmt.Errorf("'%v' is less than the minimum %v", value, low)
+		}
+		return nil
+	})
+}
+
+// Max returns a validator checking that numbers are at most high.
+func Max(high float64) ValidateFunc {
+	return eachValue(func(value any) error {
+		number, ok := numberOf(value)
+		if !ok {
+			return fmt.Errorf("'%v' is not a number", value)
+		}
+		if number > high {
+			return fmt.Errorf("'%v' is greater than the maximum %v", value, high)
+		}
+		return nil
+	})
+}
+
+// Port checks that values are port numbers, from 1 to 65535, given as
+// integers or strings.
+func Port(value any) error {
+	return eachValue(func(value any) error {
+		number, ok := numberOf(value)
+		if text, isString := value.(string); isString {
+			port, err := strconv.Atoi(text)
+			number, ok = float64(port), err == nil
+		}
+		if !ok || number < 1 || number > 65535 {
+			return fmt.Errorf("invalid port: '%v' (expected 1 to 65535)", value)
+		}
+		return nil
+	})(value)
+}
+
+// String validators
+
+// Match returns a validator checking that strings match pattern, which
+// must compile.
+func Match(pattern string) ValidateFunc {
+	matcher := regexp.MustCompile(pattern)
+	return eachValue(func(value any) error {
+		if !matcher.MatchString(fmt.Sprintf("%v", value)) {
+			return fmt.Errorf("'%v' does not match '%s'", value, pattern)
+		}
+		return nil
+	})
+}
+
+// Length returns a validator checking that strings have between low and
+// high characters, a negative high means no maximum.
+func Length(low, high int) ValidateFunc {
+	return eachValue(func(value any) error {
+		length := utf8.RuneCountInString(fmt.Sprintf("%v", value))
+		if length < low {
+			return fmt.Errorf("'%v' is shorter than %d characters", value, low)
+		}
+		if high >= 0 && length > high {
+			return fmt.Errorf("'%v' is longer than %d characters", value, high)
+		}
+		return nil
+	})
+}
+
+// URL returns a validator checking that strings are absolute URLs, with
+// one of schemes if given.
+func URL(schemes ...string) ValidateFunc {
+	return eachValue(func(value any) error {
+		parsed, ok := value.(*url.URL)
+		if !ok {
+			var err error
+			if parsed, err = url.Parse(fmt.Sprintf("%v", value)); err != nil || parsed.Scheme == "" {
+				return fmt.Errorf("invalid URL: '%v'", value)
+			}
+		}
+		if len(schemes) > 0 && !containsString(schemes, strings.ToLower(parsed.Scheme)) {
+			return fmt.Errorf("invalid URL scheme: '%s' (choose from %s)", parsed.Scheme, strings.Join(schemes, ", "))
+		}
+		return nil
+	})
+}
+
+// Path validators
+
+// pathOf returns the path of value, a string or an *os.File.
+func pathOf(value any) string {
+	if file, ok := value.(*os.File); ok {
+		return file.Name()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// PathExists checks that paths exist.
+func PathExists(value any) error {
+	return eachValue(func(value any) error {
+		if _, err := os.Stat(pathOf(value)); err != nil {
+			return fmt.Errorf("path does not exist: '%s'", pathOf(value))
+		}
+		return nil
+	})(value)
+}
+
+// IsDir checks that paths are existing directories.
+func IsDir(value any) error {
+	return eachValue(func(value any) error {
+		if info, err := os.Stat(pathOf(value)); err != nil || !info.IsDir() {
+			return fmt.Errorf("not a directory: '%s'", pathOf(value))
+		}
+		return nil
+	})(value)
+}
+
+// IsFile checks that paths are existing regular files.
+func IsFile(value any) error {
+	return eachValue(func(value any) error {
+		if info, err := os.Stat(pathOf(value)); err != nil || !info.Mode().IsRegular() {
+			return fmt.Errorf("not a file: '%s'", pathOf(value))
+		}
+		return nil
+	})(value)
+}
+
+// IsWritable checks that paths are writable: existing files can be opened
+// for writing and files can be created in existing directories or in the
+// directories of the paths that don't exist.
+func IsWritable(value any) error {
+	return eachValue(func(value any) error {
+		path := pathOf(value)
+		info, err := os.Stat(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("not writable: '%s'", path)
+			}
+			info, path = nil, filepath.Dir(path)
+			if parent, err := os.Stat(path); err != nil || !parent.IsDir() {
+				return fmt.Errorf("not writable: '%s'", pathOf(value))
+			}
+		}
+		if info == nil || info.IsDir() {
+			file, err := os.CreateTemp(path, ".argparse-*")
+			if err != nil {
+				return fmt.Errorf("not writable: '%s'", pathOf(value))
+			}
+			file.Close()
+			os.Remove(file.Name())
+			return nil
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("not writable: '%s'", path)
+		}
+		return file.Close()
+	})(value)
+}
+
+// List validators
+
+// NonEmpty checks that lists and strings are not empty.
+func NonEmpty(value any) error {
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		if reflected.Len() > 0 {
+			return nil
+		}
+	case reflect.Invalid:
+	default:
+		return nil
+	}
+	return fmt.Errorf("expected a non-empty value")
+}
+
+// Unique checks that the elements of lists are unique.
+func Unique(value any) error {
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Slice && reflected.Kind() != reflect.Array {
+		return nil
+	}
+	for i := 0; i < reflected.Len(); i++ {
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(reflected.Index(i).Interface(), reflected.Index(j).Interface()) {
+				return fmt.Errorf("duplicate value: '%v'", reflected.Index(i).Interface())
+			}
+		}
+	}
+	return nil
+}
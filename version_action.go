@@ -1,18 +1,34 @@
 package argparse
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
 )
 
-// VersionAction represents an action that displays the version information.
+// VersionAction prints the version of the program and exits, e.g. --version.
+// If the parser has a VersionJSONAction not taken yet, the version is printed
+// once it is taken or all the command line arguments are consumed, and
+// instead of an error in the arguments given after the version option.
 type VersionAction struct {
 	*Action
-	Version string
+	Version string // The version, with %(prog)s specifiers, or derived from the build info if empty
 }
 
 // NewVersionAction creates a new VersionAction.
 func NewVersionAction(argument *Argument) ActionInterface {
+	if argument.Dest == "" {
+		argument.Dest = SUPPRESS
+	}
+	if argument.Default == nil {
+		argument.Default = SUPPRESS
+	}
+	if argument.Help == "" {
+		argument.Help = "show program's version number and exit"
+	}
+
 	return &VersionAction{
 		Action: &Action{
 			OptionStrings: argument.OptionStrings,
@@ -26,24 +42,150 @@ func NewVersionAction(argument *Argument) ActionInterface {
 	}
 }
 
-// FIXME: not done
-// SetValue prints the version information and exits the program.
+// Call prints the version, or requests it to be printed once a
+// VersionJSONAction of the parser may have been taken.
 func (a *VersionAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
-	if parser != nil {
-		// Print the version information
-		// version := a.Version
-		fmt.Println("UNDONE PARSER VERSION")
-
-		// if version == "" {
-		// 	version = parser.Version // Default version if not specified
-		// }
-		// formatter := parser.GetFormatter()
-		// formatter.AddText(version)
-		// parser.PrintMessage(formatter.formatHelp(), os.Stdout)
-		fmt.Printf("Version: %s\n", a.Version)
-
-		// Exit the program after printing the version
-		os.Exit(0)
+	namespace.printVersion = a
+	if namespace.versionJSON || !parser.hasVersionJSON() {
+		parser.checkPrintVersion(namespace)
+	}
+	return nil
+}
+
+// hasVersionJSON reports whether the parser has a VersionJSONAction.
+func (ap *ArgumentParser) hasVersionJSON() bool {
+	for _, action := range ap.Actions {
+		if _, ok := action.(*VersionJSONAction); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionJSONAction makes a VersionAction print the version and the build
+// info as JSON, e.g. --version --json. Alone it does nothing.
+type VersionJSONAction struct {
+	*Action
+}
+
+// NewVersionJSONAction creates a new VersionJSONAction.
+func NewVersionJSONAction(argument *Argument) ActionInterface {
+	if argument.Dest == "" {
+		argument.Dest = SUPPRESS
+	}
+	if argument.Default == nil {
+		argument.Default = SUPPRESS
+	}
+	if argument.Help == "" {
+		argument.Help = "show the version as JSON with the version option"
+	}
+
+	return &VersionJSONAction{
+		Action: &Action{
+			OptionStrings: argument.OptionStrings,
+			Dest:          argument.Dest,
+			Nargs:         0,
+			Default:       argument.Default,
+			Help:          argument.Help,
+			Deprecated:    argument.Deprecated,
+		},
 	}
+}
+
+// Call requests the version to be printed as JSON, and prints it if a
+// VersionAction was taken before.
+func (a *VersionJSONAction) Call(parser *ArgumentParser, namespace *Namespace, values any, optionString string) error {
+	namespace.versionJSON = true
+	parser.checkPrintVersion(namespace)
 	return nil
 }
+
+// checkPrintVersion prints the version and exits if a VersionAction was taken.
+func (ap *ArgumentParser) checkPrintVersion(namespace *Namespace) {
+	action := namespace.printVersion
+	if action == nil {
+		return
+	}
+	namespace.printVersion = nil
+	ap.printMessage(ap.FormatVersion_(action, namespace.versionJSON), ap.stdout())
+	ap.Exit(0, "")
+}
+
+// FormatVersion_ returns the version printed by action, as text with the
+// %(prog)s specifiers expanded or as a JSON object with the build info.
+func (ap *ArgumentParser) FormatVersion_(action *VersionAction, asJSON bool) string {
+	info := ReadVersionInfo()
+	version := action.Version
+	if version == "" {
+		version = "%(prog)s " + info.String()
+	}
+
+	formatter := ap.GetFormatter_()
+	formatter.AddText(version)
+	text := formatter.FormatHelp()
+	if !asJSON {
+		return text
+	}
+
+	data, _ := json.MarshalIndent(struct {
+		Prog    string `json:"prog"`
+		Version string `json:"version"`
+		VersionInfo
+	}{ap.Prog, strings.TrimSpace(text), info}, "", "  ")
+	return string(data) + "\n"
+}
+
+// VersionInfo is the version of the program derived from its build info.
+type VersionInfo struct {
+	Module    string `json:"module,omitempty"`   // The path of the main module
+	Version   string `json:"moduleVersion"`      // The version of the main module, "(devel)" if built from the source tree
+	Revision  string `json:"revision,omitempty"` // The VCS revision
+	Dirty     bool   `json:"dirty"`              // Whether the working tree had local modifications
+	Time      string `json:"time,omitempty"`     // The time of the revision, in RFC 3339 format
+	GoVersion string `json:"goVersion"`          // The Go version the program was built with
+}
+
+// ReadVersionInfo returns the VersionInfo of the running program, with only
+// the Go version if the program was built without module support.
+func ReadVersionInfo() VersionInfo {
+	info := VersionInfo{Version: "(devel)", GoVersion: runtime.Version()}
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = buildInfo.Main.Path
+	if buildInfo.Main.Version != "" {
+		info.Version = buildInfo.Main.Version
+	}
+	if buildInfo.GoVersion != "" {
+		info.GoVersion = buildInfo.GoVersion
+	}
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.modified":
+			info.Dirty = setting.Value == "true"
+		case "vcs.time":
+			info.Time = setting.Value
+		}
+	}
+	return info
+}
+
+// String returns the version with the build details, e.g.
+// "v1.2.0 (revision 1a2b3c4d5e6f, dirty, 2026-01-02T15:04:05Z, go1.22.3)".
+func (info VersionInfo) String() string {
+	var details []string
+	if info.Revision != "" {
+		details = append(details, "revision "+info.Revision[:min(len(info.Revision), 12)])
+	}
+	if info.Dirty {
+		details = append(details, "dirty")
+	}
+	if info.Time != "" {
+		details = append(details, info.Time)
+	}
+	details = append(details, info.GoVersion)
+	return fmt.Sprintf("%s (%s)", info.Version, strings.Join(details, ", "))
+}